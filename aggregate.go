@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math/big"
+)
+
+type groupSum struct {
+	total   big.Rat
+	numeric bool
+}
+
+func groupRecords(records []Record, keyColumn string, sumColumns []string, normalize func(string) string, options map[string]FieldOptions) []Record {
+	var columns []string
+	for _, column := range sumColumns {
+		if column == keyColumn {
+			warnf("group-sum-key", "", column, "Колонка группировки %q не суммируется\n", column)
+			continue
+		}
+		columns = append(columns, column)
+	}
+
+	var groups []Record
+	var keys []string
+	sums := make(map[string][]groupSum)
+	skipped := make(map[string]int)
+
+	for _, record := range records {
+		key := normalize(record[keyColumn])
+		if _, exists := sums[key]; !exists {
+			group := make(Record, len(record))
+			for field, value := range record {
+				group[field] = value
+			}
+			groups = append(groups, group)
+			keys = append(keys, key)
+			sums[key] = make([]groupSum, len(columns))
+		}
+		for i, column := range columns {
+			value := record[column]
+			if value == "" {
+				continue
+			}
+			number, ok := parseDecimal(value, options[column])
+			if !ok {
+				skipped[column]++
+				continue
+			}
+			sums[key][i].total.Add(&sums[key][i].total, number)
+			sums[key][i].numeric = true
+		}
+	}
+
+	for g, group := range groups {
+		for i, column := range columns {
+			if sum := &sums[keys[g]][i]; sum.numeric {
+				group[column] = formatDecimal(&sum.total, options[column])
+			}
+		}
+	}
+
+	for _, column := range columns {
+		if skipped[column] > 0 {
+			warnf("sum-non-numeric", "", column, "В колонке %q пропущено нечисловых значений при суммировании: %d\n", column, skipped[column])
+		}
+	}
+	return groups
+}
@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestGroupRecords(t *testing.T) {
+	records := []Record{
+		{"Код": "A", "Количество": "0,1", "Цена": "10", "Название": "первый"},
+		{"Код": "B", "Количество": "5", "Цена": "нет", "Название": "второй"},
+		{"Код": "A", "Количество": "0.2", "Цена": "1 000,5", "Название": "третий"},
+		{"Код": "B", "Количество": "", "Цена": "", "Название": "четвёртый"},
+	}
+	normalize, err := parseKeyNormalization("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := map[string]FieldOptions{"Цена": {"type": "number", "round": "2"}}
+	groups := groupRecords(records, "Код", []string{"Количество", "Цена"}, normalize, options)
+	want := []Record{
+		{"Код": "A", "Количество": "0.3", "Цена": "1010.50", "Название": "первый"},
+		{"Код": "B", "Количество": "5", "Цена": "нет", "Название": "второй"},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("групп %d; ожидалось %d", len(groups), len(want))
+	}
+	for i, group := range groups {
+		for column, value := range want[i] {
+			if group[column] != value {
+				t.Errorf("группа %d, %s = %q; ожидалось %q", i, column, group[column], value)
+			}
+		}
+	}
+}
+
+func TestGroupRecordsCasefold(t *testing.T) {
+	records := []Record{
+		{"Код": "Болт", "Количество": "1"},
+		{"Код": "БОЛТ", "Количество": "2"},
+		{"Код": "Гайка", "Количество": "3"},
+	}
+	normalize, err := parseKeyNormalization("casefold,nfc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := groupRecords(records, "Код", []string{"Количество"}, normalize, nil)
+	if len(groups) != 2 || groups[0]["Код"] != "Болт" || groups[0]["Количество"] != "3" || groups[1]["Количество"] != "3" {
+		t.Errorf("группы %v", groups)
+	}
+}
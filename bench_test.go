@@ -0,0 +1,210 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	benchConfig      = "testdata/declaration.cfg"
+	benchDeclaration = "testdata/declaration.xml"
+	benchBlockOpen   = "<ESADout_CUGoods>"
+	benchBlockClose  = "</ESADout_CUGoods>"
+)
+
+var benchSizes = []int{10, 1000, 10000}
+
+func benchRun(b *testing.B, args ...string) int {
+	b.Helper()
+	devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer func() { _ = devNull.Close() }()
+	stdout, osArgs, commandLine := os.Stdout, os.Args, flag.CommandLine
+	defer func() { os.Stdout, os.Args, flag.CommandLine = stdout, osArgs, commandLine }()
+
+	flag.CommandLine = flag.NewFlagSet("xml_to_csv", flag.ContinueOnError)
+	os.Args = append([]string{"xml_to_csv"}, args...)
+	os.Stdout = devNull
+	return run()
+}
+
+func benchBlock(b *testing.B, config *Config) *etree.Element {
+	b.Helper()
+	var block strings.Builder
+	block.WriteString("<ESADout_CUGoods>")
+	i := 0
+	for xmlTag := range config.FieldMap {
+		if xmlTag == parserOpenBlockTagLiteral {
+			continue
+		}
+		fmt.Fprintf(&block, "<Group%d><Filler>-</Filler><%s>%d</%s></Group%d>", i, xmlTag, i, xmlTag, i)
+		i++
+	}
+	block.WriteString("</ESADout_CUGoods>")
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(block.String()); err != nil {
+		b.Fatal(err)
+	}
+	return doc.Root()
+}
+
+func BenchmarkCollectElements(b *testing.B) {
+	config := loadTestConfig(b, "", false)
+	block := benchBlock(b, config)
+	tags := make(map[string]bool)
+	for xmlTag := range config.FieldMap {
+		if xmlTag != parserOpenBlockTagLiteral {
+			tags[xmlTag] = true
+		}
+	}
+
+	b.Run("find-element", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			elements := make(map[string][]*etree.Element)
+			for xmlTag := range tags {
+				elements[xmlTag] = block.FindElements(".//" + xmlTag)
+			}
+		}
+	})
+	b.Run("walk", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			collectElements(block, tags, make(map[string][]*etree.Element))
+		}
+	})
+}
+
+func benchDocument(b *testing.B, blocks int) []byte {
+	b.Helper()
+	data, err := os.ReadFile(benchDeclaration)
+	if err != nil {
+		b.Fatal(err)
+	}
+	text := string(data)
+	start := strings.Index(text, benchBlockOpen)
+	end := strings.Index(text, benchBlockClose)
+	if start < 0 || end < 0 {
+		b.Fatalf("%s: нет блока %s", benchDeclaration, benchBlockOpen)
+	}
+	end += len(benchBlockClose)
+	block := text[start:end]
+
+	var doc strings.Builder
+	doc.WriteString(text[:start])
+	for i := 1; i <= blocks; i++ {
+		if i > 1 {
+			doc.WriteString("\n\t\t")
+		}
+		doc.WriteString(strings.Replace(block, "<GoodsNumeric>1<", "<GoodsNumeric>"+strconv.Itoa(i)+"<", 1))
+	}
+	doc.WriteString(text[end:])
+	return []byte(doc.String())
+}
+
+func benchWriteFiles(b *testing.B, dir string, files, blocks int) {
+	b.Helper()
+	data := benchDocument(b, blocks)
+	for i := 0; i < files; i++ {
+		name := filepath.Join(dir, fmt.Sprintf("declaration_%04d.xml", i))
+		if err := os.WriteFile(name, data, 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseXML(b *testing.B) {
+	for _, blocks := range benchSizes {
+		b.Run(fmt.Sprintf("blocks=%d", blocks), func(b *testing.B) {
+			filename := filepath.Join(b.TempDir(), "declaration.xml")
+			if err := os.WriteFile(filename, benchDocument(b, blocks), 0o644); err != nil {
+				b.Fatal(err)
+			}
+			config := loadTestConfig(b, benchConfig, true)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				records, err := parseXML(filename, config)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(records) != blocks {
+					b.Fatalf("записей %d, ожидалось %d", len(records), blocks)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkWriteCSV(b *testing.B) {
+	for _, count := range benchSizes {
+		b.Run(fmt.Sprintf("records=%d", count), func(b *testing.B) {
+			filename := filepath.Join(b.TempDir(), "declaration.xml")
+			if err := os.WriteFile(filename, benchDocument(b, count), 0o644); err != nil {
+				b.Fatal(err)
+			}
+			config := loadTestConfig(b, benchConfig, true)
+			records, err := parseXML(filename, config)
+			if err != nil {
+				b.Fatal(err)
+			}
+			output := filepath.Join(b.TempDir(), "result.csv")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := writeCSV(output, records, config); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPipeline(b *testing.B) {
+	for _, files := range []int{1, 50, 200} {
+		b.Run(fmt.Sprintf("files=%d", files), func(b *testing.B) {
+			dataDir := b.TempDir()
+			benchWriteFiles(b, dataDir, files, 100)
+			output := filepath.Join(b.TempDir(), "result.csv")
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if code := benchRun(b, "-no-defaults", "-if-exists", "overwrite", "-output", output, "--", dataDir, benchConfig); code != exitOK {
+					b.Fatalf("код %d", code)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBufferSize(b *testing.B) {
+	filename := filepath.Join(b.TempDir(), "declaration.xml")
+	if err := os.WriteFile(filename, benchDocument(b, 10000), 0o644); err != nil {
+		b.Fatal(err)
+	}
+	config := loadTestConfig(b, benchConfig, true)
+	records, err := parseXML(filename, config)
+	if err != nil {
+		b.Fatal(err)
+	}
+	output := filepath.Join(b.TempDir(), "result.csv")
+
+	for _, encoding := range []string{"utf-8", "cp1251"} {
+		for _, size := range []int{0, 4 << 10, defaultBufferSize} {
+			b.Run(fmt.Sprintf("%s/buffer=%d", encoding, size), func(b *testing.B) {
+				sized := *config
+				sized.Encoding = encoding
+				sized.BufferSize = size
+				for i := 0; i < b.N; i++ {
+					if err := writeCSV(output, records, &sized); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func ParseInto[T any](path string, cfg *Config) ([]T, error) {
+	records, err := parseXML(path, cfg)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]T, 0, len(records))
+	for i, record := range records {
+		var item T
+		if err := bindRecord(record, reflect.ValueOf(&item).Elem(), cfg.FieldOptions); err != nil {
+			return nil, fmt.Errorf(tr("запись %d файла %s: %w"), i+1, path, err)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func bindRecord(record Record, target reflect.Value, options map[string]FieldOptions) error {
+	if target.Kind() != reflect.Struct {
+		return fmt.Errorf(tr("тип %s не является структурой"), target.Type())
+	}
+	structType := target.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		column := field.Tag.Get("csv")
+		if column == "-" {
+			continue
+		}
+		if column == "" {
+			column = field.Name
+		}
+		value, ok := record[column]
+		if !ok || value == "" {
+			continue
+		}
+		if err := bindValue(target.Field(i), column, value, options[column]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindValue(field reflect.Value, column, value string, options FieldOptions) error {
+	if field.Type() == timeType {
+		t, ok := parseDate(value)
+		if !ok {
+			return fmt.Errorf(tr("колонка %q: значение %q не является датой"), column, value)
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		normalized, ok := normalizeNumber(value, options)
+		number, err := strconv.ParseInt(normalized, 10, 64)
+		if !ok || err != nil || field.OverflowInt(number) {
+			return fmt.Errorf(tr("колонка %q: значение %q не является целым числом"), column, value)
+		}
+		field.SetInt(number)
+	case reflect.Float32, reflect.Float64:
+		number, ok := parseNumber(value, options)
+		if !ok {
+			return fmt.Errorf(tr("колонка %q: значение %q не является числом"), column, value)
+		}
+		field.SetFloat(number)
+	default:
+		return fmt.Errorf(tr("колонка %q: тип поля %s не поддерживается"), column, field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type boundGoods struct {
+	Number      int       `csv:"Номер"`
+	Description string    `csv:"Название"`
+	Weight      float64   `csv:"Вес"`
+	Declared    time.Time `csv:"Дата"`
+	Comment     string    `csv:"-"`
+	Code        string
+}
+
+const bindConfig = "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\nGoodsDescription=Название\nGross=Вес\nDeclared=Дата\nCode=Code\nNote=Comment\n"
+
+func TestParseInto(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeTestFile(t, dir, "a.xml", `<ESADout_CU>
+	<ESADout_CUGoods><GoodsNumeric>1</GoodsNumeric><GoodsDescription>Болт</GoodsDescription><Gross>1 234,5</Gross><Declared>15.03.2024</Declared><Code>7318</Code><Note>x</Note></ESADout_CUGoods>
+	<ESADout_CUGoods><GoodsNumeric>2</GoodsNumeric><GoodsDescription>Гайка</GoodsDescription></ESADout_CUGoods>
+</ESADout_CU>`)
+	config := loadTestConfig(t, writeTestFile(t, dir, "cfg", bindConfig), true)
+
+	goods, err := ParseInto[boundGoods](filename, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []boundGoods{
+		{Number: 1, Description: "Болт", Weight: 1234.5, Declared: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), Code: "7318"},
+		{Number: 2, Description: "Гайка"},
+	}
+	if len(goods) != len(want) {
+		t.Fatalf("записей %d; ожидалось %d", len(goods), len(want))
+	}
+	for i := range want {
+		if goods[i] != want[i] {
+			t.Errorf("запись %d: %+v; ожидалось %+v", i+1, goods[i], want[i])
+		}
+	}
+}
+
+func TestParseIntoErrors(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeTestFile(t, dir, "a.xml", `<ESADout_CU>
+	<ESADout_CUGoods><GoodsNumeric>1.5</GoodsNumeric></ESADout_CUGoods>
+</ESADout_CU>`)
+	config := loadTestConfig(t, writeTestFile(t, dir, "cfg", bindConfig), true)
+
+	if _, err := ParseInto[boundGoods](filename, config); err == nil || !strings.Contains(err.Error(), `"1.5"`) {
+		t.Errorf("ParseInto с дробным номером: %v", err)
+	}
+	if _, err := ParseInto[string](filename, config); err == nil {
+		t.Error("ParseInto[string] не вернул ошибку")
+	}
+	if _, err := ParseInto[boundGoods](filepath.Join(dir, "missing.xml"), config); err == nil {
+		t.Error("ParseInto для несуществующего файла не вернул ошибку")
+	}
+}
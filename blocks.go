@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+var blockCounts struct {
+	mu      sync.Mutex
+	blocks  map[string]int
+	records map[string]int
+}
+
+func countBlocks(filename string, blocks int) {
+	blockCounts.mu.Lock()
+	defer blockCounts.mu.Unlock()
+	if blockCounts.blocks == nil {
+		blockCounts.blocks = make(map[string]int)
+	}
+	blockCounts.blocks[filename] += blocks
+}
+
+func countRecords(filename string, records int) {
+	blockCounts.mu.Lock()
+	defer blockCounts.mu.Unlock()
+	if blockCounts.records == nil {
+		blockCounts.records = make(map[string]int)
+	}
+	blockCounts.records[filename] += records
+}
+
+func blockTotals() (blocks, records int) {
+	blockCounts.mu.Lock()
+	defer blockCounts.mu.Unlock()
+	for _, count := range blockCounts.blocks {
+		blocks += count
+	}
+	for _, count := range blockCounts.records {
+		records += count
+	}
+	return blocks, records
+}
+
+func reportBlocks(files []string, blockTag string, written int) {
+	blockCounts.mu.Lock()
+	for _, file := range files {
+		blocks, seen := blockCounts.blocks[file]
+		if !seen {
+			continue
+		}
+		records := blockCounts.records[file]
+		if blocks != records {
+			fmt.Printf(tr("%s: блоков %s %d, записей %d (не вошло: %d)\n"), file, blockTag, blocks, records, blocks-records)
+		} else {
+			fmt.Printf(tr("%s: блоков %s %d, записей %d\n"), file, blockTag, blocks, records)
+		}
+	}
+	blockCounts.mu.Unlock()
+	blocks, records := blockTotals()
+	fmt.Printf(tr("Всего блоков %s: %d, извлечено записей: %d, записано: %d\n"), blockTag, blocks, records, written)
+}
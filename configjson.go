@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type jsonConfig struct {
+	Block     string      `json:"block"`
+	Delimiter string      `json:"delimiter"`
+	Encoding  string      `json:"encoding"`
+	Fields    []jsonField `json:"fields"`
+	SkipIf    []SkipRule  `json:"skip_if"`
+}
+
+type jsonField struct {
+	Source  string            `json:"source"`
+	Column  string            `json:"column"`
+	Options map[string]string `json:"options"`
+}
+
+func configJSONLines(data string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var parsed jsonConfig
+	if err := decoder.Decode(&parsed); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			return "", fmt.Errorf(tr("ошибка в -config-json на позиции %d: %w"), syntaxErr.Offset, err)
+		}
+		return "", fmt.Errorf(tr("ошибка в -config-json: %w"), err)
+	}
+	if decoder.More() {
+		return "", errors.New(tr("ошибка в -config-json: лишние данные после объекта"))
+	}
+
+	invalid := func(value, chars string) bool {
+		return strings.ContainsAny(value, "\r\n"+chars)
+	}
+	var lines []string
+	if parsed.Block != "" {
+		if invalid(parsed.Block, "") {
+			return "", fmt.Errorf(tr("ошибка в -config-json: недопустимый тег блока %q"), parsed.Block)
+		}
+		lines = append(lines, parserOpenBlockTagLiteral+"="+parsed.Block)
+	}
+	if parsed.Delimiter != "" {
+		if _, err := parseDelimiter(parsed.Delimiter); err != nil {
+			return "", fmt.Errorf(tr("ошибка в -config-json: %w"), err)
+		}
+		lines = append(lines, parserCSVDelimiterLiteral+"="+parsed.Delimiter)
+	}
+	if parsed.Encoding != "" {
+		lines = append(lines, parserCSVEncodingLiteral+"="+parsed.Encoding)
+	}
+	for i, field := range parsed.Fields {
+		if field.Source == "" || field.Column == "" {
+			return "", fmt.Errorf(tr("ошибка в -config-json: у поля %d не задан source или column"), i+1)
+		}
+		if invalid(field.Source, "=") || invalid(field.Column, ";") {
+			return "", fmt.Errorf(tr("ошибка в -config-json: недопустимые символы в поле %d"), i+1)
+		}
+		line := field.Source + "=" + field.Column
+		keys := make([]string, 0, len(field.Options))
+		for key := range field.Options {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if invalid(key, ";=") || invalid(field.Options[key], ";") {
+				return "", fmt.Errorf(tr("ошибка в -config-json: недопустимые символы в параметре %q поля %d"), key, i+1)
+			}
+			line += ";" + key + "=" + field.Options[key]
+		}
+		lines = append(lines, line)
+	}
+	for i, rule := range parsed.SkipIf {
+		if rule.Tag == "" || invalid(rule.Tag, "=") || invalid(rule.Value, "") {
+			return "", fmt.Errorf(tr("ошибка в -config-json: недопустимое правило skip_if %d"), i+1)
+		}
+		lines = append(lines, skipIfPrefix+rule.Tag+"="+rule.Value)
+	}
+	return strings.Join(lines, "\n"), nil
+}
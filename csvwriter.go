@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+type rowWriter interface {
+	Write(record []string) error
+	Flush()
+	Error() error
+}
+
+type quoteAllWriter struct {
+	w       *bufio.Writer
+	comma   rune
+	useCRLF bool
+	err     error
+}
+
+func newQuoteAllWriter(w io.Writer, comma rune, useCRLF bool) *quoteAllWriter {
+	return &quoteAllWriter{w: bufio.NewWriter(w), comma: comma, useCRLF: useCRLF}
+}
+
+func (q *quoteAllWriter) Write(record []string) error {
+	if q.err != nil {
+		return q.err
+	}
+	for i, field := range record {
+		if i > 0 {
+			if _, q.err = q.w.WriteRune(q.comma); q.err != nil {
+				return q.err
+			}
+		}
+		if _, q.err = q.w.WriteString(`"` + strings.ReplaceAll(field, `"`, `""`) + `"`); q.err != nil {
+			return q.err
+		}
+	}
+	lineEnd := "\n"
+	if q.useCRLF {
+		lineEnd = "\r\n"
+	}
+	_, q.err = q.w.WriteString(lineEnd)
+	return q.err
+}
+
+func (q *quoteAllWriter) Flush() {
+	if q.err == nil {
+		q.err = q.w.Flush()
+	}
+}
+
+func (q *quoteAllWriter) Error() error {
+	return q.err
+}
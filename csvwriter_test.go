@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+)
+
+func TestQuoteAllWriter(t *testing.T) {
+	var out strings.Builder
+	writer := newQuoteAllWriter(&out, ';', true)
+	rows := [][]string{{"Код", "Название"}, {"0012", `Стул "Венский"`}, {"", "a;b"}}
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "\"Код\";\"Название\"\r\n\"0012\";\"Стул \"\"Венский\"\"\"\r\n\"\";\"a;b\"\r\n"
+	if out.String() != want {
+		t.Errorf("результат %q; ожидался %q", out.String(), want)
+	}
+
+	reader := csv.NewReader(strings.NewReader(out.String()))
+	reader.Comma = ';'
+	got, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range rows {
+		if strings.Join(got[i], "|") != strings.Join(rows[i], "|") {
+			t.Errorf("строка %d прочитана как %q; ожидалось %q", i, got[i], rows[i])
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	changeColumn        = "__change"
+	changedColumnsField = "__changed_columns"
+	changeAdded         = "added"
+	changeRemoved       = "removed"
+	changeChanged       = "changed"
+)
+
+type diffSummary struct {
+	added, removed, changed int
+}
+
+func diffRecords(oldHeaders []string, oldRecords, records []Record, idColumn string, config *Config) ([]Record, []string, diffSummary) {
+	headers := getHeaders(records, config)
+	compared := make([]string, 0, len(headers))
+	for _, header := range headers {
+		if header != idColumn && header != rowNumberColumn && header != convertedAtColumn && slices.Contains(oldHeaders, header) {
+			compared = append(compared, header)
+		}
+	}
+
+	old := make(map[string]Record, len(oldRecords))
+	for _, record := range oldRecords {
+		if id := record[idColumn]; id != "" && old[id] == nil {
+			old[id] = record
+		}
+	}
+
+	var diff []Record
+	var summary diffSummary
+	seen := make(map[string]bool)
+	for _, record := range records {
+		id := record[idColumn]
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		previous, ok := old[id]
+		if !ok {
+			diff = append(diff, diffRow(record, changeAdded, nil))
+			summary.added++
+			continue
+		}
+		var changed []string
+		for _, column := range compared {
+			if record[column] != previous[column] {
+				changed = append(changed, column)
+			}
+		}
+		if len(changed) > 0 {
+			diff = append(diff, diffRow(record, changeChanged, changed))
+			summary.changed++
+		}
+	}
+	for _, record := range oldRecords {
+		id := record[idColumn]
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		diff = append(diff, diffRow(record, changeRemoved, nil))
+		summary.removed++
+	}
+
+	order := []string{changeColumn, changedColumnsField, idColumn}
+	for _, header := range slices.Concat(headers, oldHeaders) {
+		if header != rowNumberColumn && !slices.Contains(order, header) {
+			order = append(order, header)
+		}
+	}
+	return diff, order, summary
+}
+
+func diffRow(record Record, change string, changed []string) Record {
+	row := make(Record, len(record)+2)
+	for column, value := range record {
+		row[column] = value
+	}
+	delete(row, rowNumberColumn)
+	row[changeColumn] = change
+	row[changedColumnsField] = strings.Join(changed, ", ")
+	return row
+}
+
+func writeDiff(filename, against, idColumn string, records []Record, config *Config) error {
+	oldHeaders, oldRecords, err := readCSVRecords(against, config, 0)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при чтении CSV файла %s: %w"), against, err)
+	}
+	if len(oldHeaders) > 0 && !slices.Contains(oldHeaders, idColumn) {
+		return fmt.Errorf(tr("в файле %s нет колонки %q"), against, idColumn)
+	}
+
+	diff, order, summary := diffRecords(oldHeaders, oldRecords, records, idColumn, config)
+	diffConfig := reportConfig(config, order)
+	diffConfig.HeaderOnEmpty = true
+	if err := writeCSV(filename, diff, diffConfig); err != nil {
+		return err
+	}
+	fmt.Printf(tr("Сравнение с %s: добавлено %d, удалено %d, изменено %d, отчёт в %s\n"), against, summary.added, summary.removed, summary.changed, filename)
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+func applyEmbeddedMapping(filename, path string, config *Config) (int, error) {
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return 0, fmt.Errorf(tr("ошибка при чтении файла %s: %w"), filename, err)
+	}
+
+	var lines []string
+	for _, elem := range doc.FindElements(path) {
+		if source := elem.SelectAttrValue("source", ""); source != "" {
+			lines = append(lines, source+"="+elem.SelectAttrValue("column", ""))
+			continue
+		}
+		lines = append(lines, strings.Split(elem.Text(), "\n")...)
+	}
+
+	embedded := &Config{
+		FieldMap:     make(map[string]string),
+		FieldOptions: make(map[string]FieldOptions),
+		Delimiter:    config.Delimiter,
+	}
+	if err := readConfigLines(embedded, strings.NewReader(strings.Join(lines, "\n")), filepath.Dir(filename)); err != nil {
+		return 0, fmt.Errorf(tr("ошибка в сопоставлении из файла %s: %w"), filename, err)
+	}
+
+	added := 0
+	adopted := make(map[string]FieldOptions)
+	for _, csvField := range embedded.FieldOrder {
+		for xmlTag, field := range embedded.FieldMap {
+			if field != csvField {
+				continue
+			}
+			if _, exists := config.FieldMap[xmlTag]; exists {
+				continue
+			}
+			config.FieldMap[xmlTag] = csvField
+			added++
+			if !slices.Contains(config.FieldOrder, csvField) {
+				config.FieldOrder = append(config.FieldOrder, csvField)
+			}
+			if config.FieldOptions[csvField] == nil && embedded.FieldOptions[csvField] != nil {
+				config.FieldOptions[csvField] = embedded.FieldOptions[csvField]
+				adopted[csvField] = embedded.FieldOptions[csvField]
+			}
+		}
+	}
+	if err := checkFieldOptions(adopted); err != nil {
+		return 0, fmt.Errorf(tr("ошибка в сопоставлении из файла %s: %w"), filename, err)
+	}
+	addSplitColumns(config)
+	return added, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyEmbeddedMappingChecksOptions(t *testing.T) {
+	dir := t.TempDir()
+	config := loadTestConfig(t, filepath.Join(dir, "missing.cfg"), true)
+
+	valid := writeTestFile(t, dir, "valid.xml", `<Doc>
+	<MappingConfig><Field source="Sku" column="Артикул"/><Field>Price=Цена;type=number;round=2</Field></MappingConfig>
+</Doc>`)
+	added, err := applyEmbeddedMapping(valid, "//MappingConfig/Field", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added != 2 || config.FieldMap["Sku"] != "Артикул" || config.FieldOptions["Цена"]["round"] != "2" {
+		t.Errorf("добавлено %d, FieldMap %v, FieldOptions %v", added, config.FieldMap, config.FieldOptions)
+	}
+
+	invalid := writeTestFile(t, dir, "invalid.xml", `<Doc>
+	<MappingConfig><Field>Weight=Вес;type=number;round=abc</Field></MappingConfig>
+</Doc>`)
+	if _, err := applyEmbeddedMapping(invalid, "//MappingConfig/Field", config); err == nil {
+		t.Error("недопустимый round=abc из XML не отклонён")
+	}
+}
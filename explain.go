@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+func (s fieldSource) label() string {
+	if s.attr != "" {
+		return s.path + "@" + s.attr
+	}
+	return s.path
+}
+
+func explainField(block *etree.Element, mapping fieldMapping, elements map[string][]*etree.Element, config *Config) string {
+	unwrap := config.FieldOptions[mapping.csvField]["unwrap"] == "true"
+	trim := trimField(config, mapping.csvField)
+	maxDepth, _ := strconv.Atoi(config.FieldOptions[mapping.csvField]["max-depth"])
+	var empty []string
+	for _, source := range mapping.sources {
+		elems := elements[source.path]
+		if maxDepth > 0 {
+			elems = withinDepth(elems, block, maxDepth)
+		}
+		value, ok := source.value(elems, config, unwrap, trim)
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(value) != "" {
+			return fmt.Sprintf(tr("найдено в %s: %q"), source.label(), value)
+		}
+		empty = append(empty, source.label())
+	}
+	if len(empty) > 0 {
+		return fmt.Sprintf(tr("элемент пуст: %s"), strings.Join(empty, ", "))
+	}
+	labels := make([]string, len(mapping.sources))
+	for i, source := range mapping.sources {
+		labels[i] = source.label()
+	}
+	return fmt.Sprintf(tr("элемент не найден: %s"), strings.Join(labels, ", "))
+}
+
+func explainFile(filename string, config *Config, reject func(Record) string, out io.Writer) error {
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при чтении файла %s: %w"), filename, err)
+	}
+	blockTag := config.FieldMap[parserOpenBlockTagLiteral]
+	blocks, err := findBlocks(doc, blockTag)
+	if err != nil {
+		return err
+	}
+	indices := config.Blocks
+	if len(indices) == 0 {
+		for i := range blocks {
+			indices = append(indices, i+1)
+		}
+	}
+
+	mappings, countFields := compileMappings(config)
+	tags, paths := mappingSources(mappings)
+	byColumn := make(map[string]fieldMapping)
+	for _, mapping := range mappings {
+		byColumn[mapping.csvField] = mapping
+	}
+	countColumns := make(map[string]bool)
+	for _, column := range countFields {
+		countColumns[column] = true
+	}
+	documentFields := documentValues(doc, config.DocumentFields)
+
+	for _, index := range indices {
+		if index > len(blocks) {
+			continue
+		}
+		block := blocks[index-1]
+		fmt.Fprintf(out, tr("Файл %s, блок %d %s\n"), filename, index, elementPath(block))
+		if !matchesFilters(block, config.BlockFilters) {
+			fmt.Fprintln(out, tr("  блок не подходит под -block-filter"))
+			continue
+		}
+		if rule, skipped := matchingSkipRule(block, config.SkipRules); skipped {
+			fmt.Fprintf(out, tr("  блок пропущен правилом skip-if:%s=%s\n"), rule.Tag, rule.Value)
+			continue
+		}
+
+		blockConfig := *config
+		blockConfig.Blocks = []int{index}
+		recs, err := extractRecords(doc, filename, &blockConfig)
+		if err != nil {
+			return err
+		}
+		if len(recs) == 0 {
+			fmt.Fprintln(out, tr("  запись пуста и не попадает в результат"))
+			continue
+		}
+		record := recs[0]
+		for column, value := range documentFields {
+			record[column] = value
+		}
+		addKeyColumns(record, config)
+		addMoneyColumns(record, config)
+
+		elements := gatherElements(block, tags, paths)
+		for _, column := range getHeaders(recs, config) {
+			var status string
+			if mapping, ok := byColumn[column]; ok {
+				status = explainField(block, mapping, elements, config)
+			} else if countColumns[column] {
+				status = tr("число элементов")
+			} else if _, ok := record[column]; ok {
+				status = tr("вычислено")
+			} else {
+				status = tr("нет значения")
+			}
+			fmt.Fprintf(out, "  %s: %s → %q\n", column, status, record[column])
+		}
+		if reason := reject(record); reason != "" {
+			fmt.Fprintf(out, tr("  запись отброшена: %s\n"), reason)
+		}
+	}
+	return nil
+}
+
+func matchingSkipRule(block *etree.Element, rules []SkipRule) (SkipRule, bool) {
+	for _, rule := range rules {
+		if shouldSkip(block, []SkipRule{rule}) {
+			return rule, true
+		}
+	}
+	return SkipRule{}, false
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	rangeModeWarn   = "warn"
+	rangeModeReject = "reject"
+)
+
+func checkRanges(records []Record, config *Config, mode string, rejected []Rejection) ([]Record, []Rejection) {
+	fields := make([]string, 0, len(config.FieldOptions))
+	for field, options := range config.FieldOptions {
+		if options["min"] != "" || options["max"] != "" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) == 0 {
+		return records, rejected
+	}
+	sort.Strings(fields)
+
+	kept := records[:0]
+	for _, record := range records {
+		reason, violated := "", ""
+		for _, field := range fields {
+			if reason = rangeViolation(record[field], field, config.FieldOptions[field]); reason != "" {
+				violated = field
+				break
+			}
+		}
+		if reason == "" {
+			kept = append(kept, record)
+			continue
+		}
+		if mode == rangeModeReject {
+			rejected = append(rejected, Rejection{Record: record, Reason: reason})
+			continue
+		}
+		warnf("range", "", violated, "Значение вне допустимого диапазона: %s\n", reason)
+		kept = append(kept, record)
+	}
+	return kept, rejected
+}
+
+func rangeViolation(value, field string, options FieldOptions) string {
+	number, ok := parseNumber(value, options)
+	if !ok {
+		return ""
+	}
+	if limit, ok := parseNumber(options["min"], nil); ok && number < limit {
+		return fmt.Sprintf("min:%s=%s (%s)", field, options["min"], value)
+	}
+	if limit, ok := parseNumber(options["max"], nil); ok && number > limit {
+		return fmt.Sprintf("max:%s=%s (%s)", field, options["max"], value)
+	}
+	return ""
+}
+
+func checkUniqueIDs(records []Record, column string, normalize func(string) string) int {
+	counts := make(map[string]int)
+	var order []string
+	first := make(map[string]string)
+	empty := 0
+	for _, record := range records {
+		id := record[column]
+		if id == "" {
+			empty++
+			continue
+		}
+		key := normalize(id)
+		if counts[key] == 0 {
+			order = append(order, key)
+			first[key] = id
+		}
+		counts[key]++
+	}
+
+	duplicates := 0
+	for _, key := range order {
+		if counts[key] > 1 {
+			warnf("duplicate-id", "", column, "Значение %q колонки %q повторяется (записей: %d)\n", first[key], column, counts[key])
+			duplicates++
+		}
+	}
+	if empty > 0 {
+		warnf("missing-id", "", column, "Записей без значения в колонке %q: %d\n", column, empty)
+	}
+	if duplicates > 0 {
+		fmt.Printf(tr("Неуникальных значений в колонке %q: %d\n"), column, duplicates)
+	}
+	return duplicates
+}
+
+func parseDateBound(value string, upper bool) (time.Time, error) {
+	t, ok := parseDate(value)
+	if !ok {
+		return time.Time{}, fmt.Errorf(tr("неверная дата %q"), value)
+	}
+	if upper && !strings.ContainsAny(value, "T:") {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+func filterDates(records []Record, column string, since, until time.Time, rejected []Rejection) ([]Record, []Rejection) {
+	kept := records[:0]
+	for _, record := range records {
+		value := record[column]
+		t, ok := parseDate(value)
+		var reason string
+		switch {
+		case !ok:
+			reason = fmt.Sprintf("date:%s (%s)", column, value)
+		case !since.IsZero() && t.Before(since):
+			reason = fmt.Sprintf("since:%s (%s)", column, value)
+		case !until.IsZero() && t.After(until):
+			reason = fmt.Sprintf("until:%s (%s)", column, value)
+		}
+		if reason != "" {
+			rejected = append(rejected, Rejection{Record: record, Reason: reason})
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept, rejected
+}
+
+func dropIdenticalColumns(records []Record, config *Config) []string {
+	headers := getHeaders(records, config)
+	var dropped []string
+	removed := make(map[string]bool)
+	for i, first := range headers {
+		if removed[first] {
+			continue
+		}
+		for _, second := range headers[i+1:] {
+			if removed[second] {
+				continue
+			}
+			identical, filled := true, false
+			for _, record := range records {
+				if record[first] != record[second] {
+					identical = false
+					break
+				}
+				filled = filled || record[first] != ""
+			}
+			if identical && filled {
+				removed[second] = true
+				dropped = append(dropped, second)
+				warnf("identical-column", "", second, "Колонка %q совпадает с колонкой %q и удалена\n", second, first)
+			}
+		}
+	}
+	if len(dropped) == 0 {
+		return nil
+	}
+	for _, record := range records {
+		for _, column := range dropped {
+			delete(record, column)
+		}
+	}
+	config.FieldOrder = slices.DeleteFunc(config.FieldOrder, func(field string) bool { return removed[field] })
+	return dropped
+}
+
+func distinctValues(records []Record, column string, config *Config, normalize func(string) string) []Record {
+	seen := make(map[string]bool)
+	var values []string
+	for _, record := range records {
+		value := record[column]
+		if value == "" || seen[normalize(value)] {
+			continue
+		}
+		seen[normalize(value)] = true
+		values = append(values, value)
+	}
+	sort.SliceStable(values, func(i, j int) bool {
+		c, _ := compareValues(values[i], values[j], config.FieldOptions[column])
+		return c < 0
+	})
+	distinct := make([]Record, len(values))
+	for i, value := range values {
+		distinct[i] = Record{column: value}
+	}
+	return distinct
+}
+
+func checkNumericColumns(filename string, records []Record, columns []string, options map[string]FieldOptions) int {
+	invalid := 0
+	for i, record := range records {
+		for _, column := range columns {
+			value := record[column]
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			if _, ok := parseNumber(value, options[column]); !ok {
+				warnf("not-a-number", filename, column, "%s, строка %d, колонка %q: не число %q\n", filename, i+1, column, value)
+				invalid++
+			}
+		}
+	}
+	return invalid
+}
+
+func checkMaxRows(total, limit int) error {
+	if limit > 0 && total > limit {
+		return fmt.Errorf(tr("в результате %d записей, больше допустимого -max-rows %d; результат не записан"), total, limit)
+	}
+	return nil
+}
+
+func parseDuplicateKeys(value string) ([]string, error) {
+	var keys []string
+	for _, key := range strings.Split(strings.TrimPrefix(value, "keys="), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf(tr("не указаны колонки ключа в %q, ожидается keys=Колонка1,Колонка2"), value)
+	}
+	return keys, nil
+}
+
+func markDuplicates(records []Record, keys []string, normalize func(string) string) int {
+	groups := make(map[string][]int)
+	var order []string
+	for i, record := range records {
+		values := make([]string, len(keys))
+		for j, key := range keys {
+			values[j] = normalize(record[key])
+		}
+		id := strings.Join(values, "\x00")
+		if groups[id] == nil {
+			order = append(order, id)
+		}
+		groups[id] = append(groups[id], i)
+	}
+
+	duplicates := 0
+	for _, id := range order {
+		members := groups[id]
+		group := ""
+		if len(members) > 1 {
+			duplicates++
+			group = strconv.Itoa(duplicates)
+		}
+		for _, i := range members {
+			records[i][dupGroupColumn] = group
+			records[i][dupCountColumn] = strconv.Itoa(len(members))
+		}
+	}
+	return duplicates
+}
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+type runOptions struct {
+	withSource            bool
+	withXPath             bool
+	noDefaults            bool
+	warnEmptyColumns      bool
+	emptyThreshold        float64
+	encoding              string
+	output                string
+	timestampFormat       string
+	trim                  bool
+	outDir                string
+	delimiter             string
+	head                  int
+	validateSample        string
+	inferSample           string
+	printConfig           bool
+	workers               int
+	timeout               time.Duration
+	failFast              bool
+	required              stringList
+	rejectsFile           string
+	stripInvisible        bool
+	maxOpenFiles          int
+	xinclude              bool
+	multiDoc              bool
+	permissive            bool
+	preserveCData         bool
+	noDTD                 bool
+	gzipOutput            bool
+	schemaFile            string
+	eol                   string
+	preamble              stringList
+	httpTimeout           time.Duration
+	httpHeaders           stringList
+	httpUser              string
+	readRetries           int
+	groupBy               string
+	sumColumns            stringList
+	onDuplicate           string
+	duplicateSeparator    string
+	blocks                string
+	strictRows            bool
+	perFile               bool
+	sortColumns           bool
+	columnCollation       string
+	lockSchema            bool
+	checksum              string
+	noNumberAutodetect    bool
+	groupSep              string
+	noEmptyOutput         bool
+	postCommand           string
+	statusFD              int
+	onComplete            string
+	webhook               string
+	langAttr              string
+	langAttrName          string
+	qualityFile           string
+	zipOutput             string
+	strictMapping         bool
+	unmappedMode          string
+	keyColumns            stringList
+	keySeparator          string
+	moneyColumns          stringList
+	moneyFormat           string
+	moneyLocale           string
+	moneyPlaces           int
+	blockFilters          stringList
+	sortKeys              stringList
+	columns               string
+	columnsFrom           string
+	translit              bool
+	rangeMode             string
+	mergeCSV              string
+	transpose             bool
+	transposeLimit        int
+	withTimestamp         bool
+	timestampZone         string
+	ifExists              string
+	stateFile             string
+	withRaw               bool
+	idColumn              string
+	validateNumeric       string
+	numericStrict         bool
+	diffAgainst           string
+	embedMetadata         bool
+	maxRows               int
+	partitionAttr         string
+	fileTotals            string
+	fileTotalsOutput      string
+	diffOutput            string
+	idStrict              bool
+	normalizeUnicode      string
+	maxBlocks             int
+	onMissing             string
+	maxBlocksMode         string
+	chunkSize             int
+	interactive           bool
+	alsoOutput            stringList
+	format                string
+	stats                 bool
+	blockSummary          bool
+	timingsTop            int
+	timingsFile           string
+	statsFile             string
+	mergeDelimiter        string
+	headerOnEmpty         bool
+	embeddedMapping       string
+	minAge                time.Duration
+	skipFiles             int
+	fileOrder             string
+	templateHeader        string
+	twoPass               bool
+	numbersAsStrings      bool
+	flattenAttributes     bool
+	flattenLeafAttributes bool
+	autoMapPrefix         string
+	autoMapCollision      string
+	autoMap               bool
+	spillThreshold        int
+	configJSON            string
+	nullValues            string
+	withBreadcrumb        bool
+	expectRoot            string
+	dateColumn            string
+	since                 string
+	until                 string
+	rowNumber             bool
+	traceFile             string
+	warningsFile          string
+	blankAsEmpty          bool
+	fileList              string
+	dedupeNormalize       string
+	markDups              string
+	distinct              string
+	dropIdentical         bool
+	explain               bool
+	bufferSize            int
+	rowWorkers            int
+	quoteAll              bool
+}
+
+func defineFlags(fs *flag.FlagSet) *runOptions {
+	opts := &runOptions{}
+	fs.BoolVar(&opts.withSource, "with-source", false, fmt.Sprintf(tr("добавить колонку %s с путём исходного XML файла вместе с каталогом"), sourceColumn))
+	fs.BoolVar(&opts.withXPath, "xpath", false, fmt.Sprintf(tr("добавить колонку %s с путём блока в исходном XML"), xpathColumn))
+	fs.BoolVar(&opts.noDefaults, "no-defaults", false, tr("не использовать встроенные сопоставления, только из файла конфигурации"))
+	fs.BoolVar(&opts.warnEmptyColumns, "warn-empty-columns", false, tr("сообщать о колонках, пустых в большинстве записей"))
+	fs.Float64Var(&opts.emptyThreshold, "empty-threshold", 1.0, tr("доля пустых значений (0..1), начиная с которой колонка считается пустой"))
+	fs.StringVar(&opts.encoding, "encoding", "", tr("кодировка результата: utf8, utf8-bom, cp1251 или utf16le (по умолчанию cp1251 в Windows, utf8 в остальных системах)"))
+	fs.StringVar(&opts.output, "output", "", tr("имя файла результата (по умолчанию result_<время>.csv)"))
+	fs.StringVar(&opts.timestampFormat, "timestamp-format", defaultTimestampFormat, tr("формат времени Go для имени файла по умолчанию"))
+	fs.BoolVar(&opts.trim, "trim", false, tr("обрезать пробельные символы по краям значений, включая содержимое CDATA"))
+	fs.StringVar(&opts.outDir, "out-dir", "", tr("каталог для файла результата; -output считается относительно него, если путь не абсолютный"))
+	fs.StringVar(&opts.delimiter, "delimiter", "", tr("разделитель полей CSV (по умолчанию ';', \\t для табуляции)"))
+	fs.IntVar(&opts.head, "head", 0, tr("показать первые N записей таблицей и выйти, не записывая результат"))
+	fs.StringVar(&opts.validateSample, "validate-config", "", tr("проверить, что все теги и атрибуты конфигурации встречаются в XML файле или схеме XSD, и выйти"))
+	fs.StringVar(&opts.inferSample, "infer-config", "", tr("вывести черновик конфигурации, подобранный по XML файлу, и выйти"))
+	fs.BoolVar(&opts.printConfig, "print-config", false, tr("вывести итоговую конфигурацию в формате файла конфигурации и выйти"))
+	fs.IntVar(&opts.workers, "workers", runtime.NumCPU(), tr("число файлов, обрабатываемых одновременно"))
+	fs.DurationVar(&opts.timeout, "timeout", 2*time.Minute, tr("максимальное время обработки всех файлов"))
+	fs.BoolVar(&opts.failFast, "fail-fast", false, tr("прервать обработку при первой ошибке чтения файла"))
+	fs.Var(&opts.required, "require", tr("отбросить записи с пустым значением колонки (можно указать несколько раз)"))
+	fs.StringVar(&opts.rejectsFile, "rejects", "", tr("записать отброшенные записи с причиной в отдельный CSV файл"))
+	fs.BoolVar(&opts.stripInvisible, "strip-invisible", false, tr("удалять из значений невидимые символы U+200B, U+200C, U+200D, U+2060 и U+FEFF"))
+	fs.IntVar(&opts.maxOpenFiles, "max-open-files", defaultMaxOpenFiles, tr("максимальное число одновременно открытых XML файлов"))
+	fs.BoolVar(&opts.xinclude, "xinclude", false, tr("раскрывать локальные включения xi:include"))
+	fs.BoolVar(&opts.multiDoc, "multi-doc", false, tr("файл может содержать несколько XML документов подряд, каждый со своим объявлением <?xml ...?>"))
+	fs.BoolVar(&opts.permissive, "permissive", false, tr("разбирать XML с типичными ошибками: атрибуты без значения или кавычек, неэкранированный &"))
+	fs.BoolVar(&opts.preserveCData, "preserve-cdata", false, tr("сохранять разделы CDATA в колонке -with-raw, а не экранировать их содержимое"))
+	fs.BoolVar(&opts.noDTD, "no-dtd", false, tr("отклонять файлы с объявлением DOCTYPE"))
+	fs.BoolVar(&opts.gzipOutput, "gzip", false, tr("сжимать результат gzip (включается автоматически для -output с расширением .gz)"))
+	fs.StringVar(&opts.schemaFile, "schema", "", tr("записать описание колонок результата (имя, тип, заполненность) в JSON файл"))
+	fs.StringVar(&opts.eol, "eol", "lf", tr("окончание строк результата: lf или crlf"))
+	fs.Var(&opts.preamble, "preamble", tr("строка, записываемая перед заголовком как есть (можно указать несколько раз)"))
+	fs.DurationVar(&opts.httpTimeout, "http-timeout", defaultHTTPTimeout, tr("максимальное время загрузки одного XML файла по адресу http:// или https://"))
+	fs.Var(&opts.httpHeaders, "http-header", tr("заголовок запроса при загрузке XML по HTTP, \"ИМЯ: ЗНАЧЕНИЕ\" (можно указать несколько раз)"))
+	fs.StringVar(&opts.httpUser, "http-user", "", fmt.Sprintf(tr("пользователь и пароль для загрузки XML по HTTP (Basic), ПОЛЬЗОВАТЕЛЬ:ПАРОЛЬ; без пароля он берётся из переменной %s"), httpPasswordEnv))
+	fs.IntVar(&opts.readRetries, "read-retries", 0, tr("число повторных попыток чтения файла при ошибках ввода-вывода"))
+	fs.BoolVar(&verbose, "verbose", false, tr("подробный вывод"))
+	fs.BoolVar(&noPause, "no-pause", false, tr("не ждать нажатия Enter перед выходом в Windows"))
+	fs.StringVar(&opts.groupBy, "group-by", "", tr("свести записи по значению колонки в одну строку на группу"))
+	fs.Var(&opts.sumColumns, "sum", tr("колонка, суммируемая внутри группы -group-by (можно указать несколько раз)"))
+	fs.StringVar(&opts.onDuplicate, "on-dup", duplicateFirst, tr("какой из повторяющихся элементов блока брать: first, last или join"))
+	fs.StringVar(&opts.duplicateSeparator, "dup-separator", ", ", tr("разделитель значений для -on-dup join"))
+	fs.StringVar(&opts.blocks, "blocks", "", tr("номера блоков в каждом файле через запятую, начиная с 1 (по умолчанию все)"))
+	fs.BoolVar(&opts.strictRows, "strict-rows", false, tr("завершиться с ошибкой, если в записях есть колонки вне заданного порядка полей"))
+	fs.BoolVar(&opts.perFile, "per-file", false, tr("записать результат каждого XML файла в отдельный CSV с тем же именем"))
+	fs.BoolVar(&opts.sortColumns, "sort-columns", false, tr("упорядочить колонки результата по алфавиту их имён вместо порядка конфигурации"))
+	fs.StringVar(&opts.columnCollation, "column-collation", collationBytes, tr("порядок имён для -sort-columns: bytes (по кодам символов) или ru (русский алфавит, ё рядом с е, без учёта регистра)"))
+	fs.BoolVar(&opts.lockSchema, "lock-schema", false, tr("в режиме -per-file использовать общий набор колонок для всех файлов"))
+	fs.StringVar(&opts.checksum, "checksum", "", tr("записать рядом с результатом файл контрольной суммы: sha256 или md5"))
+	fs.BoolVar(&opts.noNumberAutodetect, "no-number-autodetect", false, tr("не угадывать разделители разрядов и дробной части чисел: колонкам type=number и type=int нужен параметр decimal= (и group=, если есть разделители разрядов), остальные значения считаются числами только в виде 1234.5"))
+	fs.StringVar(&opts.groupSep, "group-sep", "", tr("символы-разделители разрядов чисел, удаляемые перед разбором, например ' или ."))
+	fs.BoolVar(&opts.noEmptyOutput, "no-empty-output", false, tr("не создавать файл результата без записей (даже с -header-on-empty) и завершаться с кодом 3 или 4"))
+	fs.StringVar(&opts.postCommand, "post-command", "", tr("пропустить каждый записываемый файл результата через внешнюю команду (через sh -c или cmd /C): команда читает данные из stdin, её stdout записывается в файл"))
+	fs.IntVar(&opts.statusFD, "status-fd", 0, tr("писать ход обработки и итоги в JSON Lines в открытый дескриптор файла с этим номером (0 — не писать)"))
+	fs.StringVar(&opts.onComplete, "on-complete", "", tr("команда, выполняемая после успешной записи результата (через sh -c или cmd /C)"))
+	fs.StringVar(&opts.webhook, "webhook", "", tr("адрес, на который после успешной записи результата отправляется POST с итогами в JSON"))
+	fs.StringVar(&opts.langAttr, "lang-attr", "", tr("из одноимённых элементов с атрибутом языка брать элемент на этом языке, например ru (иначе первый)"))
+	fs.StringVar(&opts.langAttrName, "lang-attr-name", "xml:lang", tr("атрибут языка для -lang-attr"))
+	fs.StringVar(&opts.qualityFile, "quality", "", tr("записать в CSV файл показатели качества каждой колонки: число строк, заполненных, пустых и различных значений, минимум и максимум чисел"))
+	fs.StringVar(&opts.zipOutput, "zip-output", "", tr("записать файлы результата записями одного ZIP архива вместо отдельных файлов"))
+	fs.BoolVar(&opts.strictMapping, "strict-mapping", false, tr("сообщить о непустых конечных элементах блоков, не сопоставленных ни одной колонке"))
+	fs.StringVar(&opts.unmappedMode, "unmapped-mode", unmappedError, tr("что делать при несопоставленных элементах -strict-mapping: warn или error"))
+	fs.Var(&opts.keyColumns, "key-column", tr("добавить колонку с составным ключом из колонок результата, ИМЯ=КОЛОНКА1+КОЛОНКА2 (можно указать несколько раз)"))
+	fs.StringVar(&opts.keySeparator, "key-separator", "|", tr("разделитель частей -key-column"))
+	fs.Var(&opts.moneyColumns, "money-column", tr("добавить колонку с суммой и кодом валюты, ИМЯ=КОЛОНКА_СУММЫ+КОЛОНКА_ВАЛЮТЫ (можно указать несколько раз)"))
+	fs.StringVar(&opts.moneyFormat, "money-format", "{amount} {currency}", tr("шаблон значения -money-column с подстановками {amount} и {currency}"))
+	fs.StringVar(&opts.moneyLocale, "money-locale", localePlain, tr("запись суммы в -money-column: plain (1234.50), ru (1 234,50) или en (1,234.50)"))
+	fs.IntVar(&opts.moneyPlaces, "money-places", 2, tr("число знаков после запятой в сумме -money-column"))
+	fs.Var(&opts.blockFilters, "block-filter", tr("обрабатывать только блоки с атрибутом, равным значению, АТРИБУТ=ЗНАЧЕНИЕ (можно указать несколько раз, должны выполняться все)"))
+	fs.Var(&opts.sortKeys, "sort", tr("сортировать записи по колонке, КОЛОНКА[:desc] (можно указать несколько раз)"))
+	fs.StringVar(&opts.columns, "columns", "", tr("колонки результата и их порядок через запятую"))
+	fs.StringVar(&opts.columnsFrom, "columns-from", "", tr("файл со списком колонок результата, по одной в строке"))
+	fs.BoolVar(&opts.translit, "translit", false, tr("транслитерировать кириллицу латиницей во всех колонках (ICAO Doc 9303)"))
+	fs.StringVar(&opts.rangeMode, "range-mode", rangeModeWarn, tr("действие при нарушении min=/max= поля: warn или reject"))
+	fs.StringVar(&opts.mergeCSV, "merge-csv", "", tr("объединить готовые CSV файлы (через запятую) в один вместо обработки XML"))
+	fs.BoolVar(&opts.transpose, "transpose", false, tr("записать результат транспонированным: поле в строке, запись в колонке"))
+	fs.IntVar(&opts.transposeLimit, "transpose-limit", 50, tr("максимальное число записей для -transpose"))
+	fs.BoolVar(&opts.withTimestamp, "with-timestamp", false, fmt.Sprintf(tr("добавить колонку %s со временем конвертации (RFC3339)"), convertedAtColumn))
+	fs.StringVar(&opts.timestampZone, "timestamp-zone", "utc", fmt.Sprintf(tr("часовой пояс для %s: utc или local"), convertedAtColumn))
+	fs.StringVar(&opts.ifExists, "if-exists", "", tr("если файл результата существует: overwrite, skip, error или rename (по умолчанию overwrite, с -output — error)"))
+	fs.StringVar(&opts.stateFile, "state", "", tr("файл состояния: пропускать XML файлы, не изменившиеся с прошлого запуска"))
+	fs.BoolVar(&opts.withRaw, "with-raw", false, fmt.Sprintf(tr("добавить колонку %s с XML каждого блока"), rawColumn))
+	fs.StringVar(&opts.idColumn, "id-column", "", tr("проверить уникальность значений колонки во всех записях"))
+	fs.StringVar(&opts.validateNumeric, "validate-numeric", "", tr("проверить, что в колонках через запятую записаны только числа, и сообщить о каждом нечисловом значении"))
+	fs.BoolVar(&opts.numericStrict, "numeric-strict", false, tr("завершиться с ошибкой, если -validate-numeric нашёл нечисловые значения"))
+	fs.StringVar(&opts.diffAgainst, "diff-against", "", tr("сравнить результат с CSV файлом прошлого запуска по колонке -id-column и записать отчёт о добавленных, удалённых и изменённых строках"))
+	fs.BoolVar(&opts.embedMetadata, "embed-metadata", false, tr("записать в начало результата сведения о запуске: версию программы, время, каталог входных файлов, их число и итоговую конфигурацию"))
+	fs.IntVar(&opts.maxRows, "max-rows", 0, tr("завершиться ошибкой, не записывая результат, если в нём больше N записей (0 — без ограничения)"))
+	fs.StringVar(&opts.partitionAttr, "partition-by-attr", "", tr("записать блоки в отдельные файлы по значению атрибута блока (или ближайшего предка), добавляя его к имени файла результата"))
+	fs.StringVar(&opts.fileTotals, "file-totals", "", tr("записать в отдельный CSV суммы перечисленных через запятую колонок по каждому XML файлу и общий итог"))
+	fs.StringVar(&opts.fileTotalsOutput, "file-totals-output", "", tr("файл отчёта -file-totals (по умолчанию имя результата с суффиксом _totals)"))
+	fs.StringVar(&opts.diffOutput, "diff-output", "", tr("файл отчёта -diff-against (по умолчанию имя результата с суффиксом _diff)"))
+	fs.BoolVar(&opts.idStrict, "id-strict", false, tr("завершиться с ошибкой, если значения -id-column повторяются"))
+	fs.StringVar(&opts.normalizeUnicode, "normalize-unicode", "", tr("привести значения к форме Unicode: nfc или nfd"))
+	fs.IntVar(&opts.maxBlocks, "max-blocks-per-file", 0, tr("наибольшее допустимое число блоков в одном файле (0 — без ограничения)"))
+	fs.StringVar(&opts.onMissing, "on-missing", missingEmpty, tr("что делать, если элемент колонки не найден в блоке: empty (пустое значение), skip-record (пропустить запись) или error (ошибка)"))
+	fs.StringVar(&opts.maxBlocksMode, "max-blocks-mode", maxBlocksError, tr("что делать при превышении -max-blocks-per-file: warn или error"))
+	fs.IntVar(&opts.chunkSize, "chunk-size", 0, tr("записывать результат частями не более чем по N строк (0 — одним файлом)"))
+	fs.BoolVar(&opts.interactive, "interactive", false, tr("выбрать поля и имена колонок по первому XML файлу в диалоге"))
+	fs.Var(&opts.alsoOutput, "also-output", tr("дополнительно записать те же записи в файл другого формата: ФОРМАТ=ФАЙЛ, например ndjson=out.ndjson (можно указать несколько раз)"))
+	fs.StringVar(&opts.format, "format", formatCSV, tr("формат результата: csv, xml, json, ndjson, parquet или tsv-excel (CSV с табуляцией в UTF-16LE для Excel)"))
+	fs.BoolVar(&opts.stats, "stats", false, tr("вывести число различных значений и самые частые значения каждой колонки"))
+	fs.BoolVar(&opts.blockSummary, "block-summary", false, tr("вывести по каждому файлу и всего число найденных блоков и извлечённых записей"))
+	fs.IntVar(&opts.timingsTop, "timings", 0, tr("замерить время разбора каждого файла и вывести N самых медленных"))
+	fs.StringVar(&opts.timingsFile, "timings-file", "", tr("записать время разбора всех файлов в CSV файл, от самых медленных"))
+	fs.StringVar(&opts.statsFile, "stats-file", "", tr("записать статистику -stats в файл вместо вывода на экран"))
+	fs.StringVar(&opts.mergeDelimiter, "merge-delimiter", "auto", tr("разделитель входных файлов -merge-csv (auto — определить по первой строке)"))
+	fs.BoolVar(&opts.headerOnEmpty, "header-on-empty", false, tr("при отсутствии записей записать файл только с заголовком"))
+	fs.StringVar(&lang, "lang", lang, tr("язык сообщений: ru или en"))
+	fs.StringVar(&opts.embeddedMapping, "embedded-mapping", "", tr("путь etree к элементам с сопоставлениями внутри первого XML файла, например //MappingConfig/Field"))
+	fs.DurationVar(&opts.minAge, "min-age", 0, tr("пропускать XML файлы, изменённые позже, чем указанное время назад, например 30s (файлы, которые ещё записываются)"))
+	fs.IntVar(&opts.skipFiles, "skip-files", 0, tr("пропустить первые N XML файлов (в порядке обработки)"))
+	fs.StringVar(&opts.fileOrder, "order", "", tr("порядок обработки XML файлов: name, mtime или mtime-desc (по умолчанию по имени в каждом каталоге, для -files — порядок списка)"))
+	fs.StringVar(&opts.templateHeader, "template-header", "", tr("точный заголовок результата через разделитель полей, например \"a;b;c\""))
+	fs.BoolVar(&opts.twoPass, "two-pass", false, tr("читать XML файлы дважды: сначала собрать колонки, затем записывать строки без хранения всех записей в памяти"))
+	fs.BoolVar(&opts.numbersAsStrings, "numbers-as-strings", false, tr("в JSON и NDJSON записывать поля type=number строками"))
+	fs.BoolVar(&opts.flattenAttributes, "flatten-attributes", false, tr("добавить колонку @атрибут для каждого атрибута блока"))
+	fs.BoolVar(&opts.flattenLeafAttributes, "flatten-leaf-attributes", false, tr("вместе с -flatten-attributes добавить колонки тег@атрибут для атрибутов конечных элементов блока"))
+	fs.StringVar(&opts.autoMapPrefix, "auto-map-prefix", prefixStrip, tr("префикс пространства имён в именах колонок -auto-map: strip (только локальное имя) или keep (ns:Tag)"))
+	fs.StringVar(&opts.autoMapCollision, "auto-map-collision", collisionFirst, tr("что делать, если теги с разными префиксами дают одну колонку -auto-map: first (взять первый), suffix (добавить _2, _3) или error"))
+	fs.BoolVar(&opts.autoMap, "auto-map", false, tr("добавить колонку для каждого конечного элемента блока с именем тега в качестве имени колонки"))
+	fs.IntVar(&opts.spillThreshold, "spill-threshold", 0, tr("хранить в памяти не более N записей, остальные сохранять во временный файл (0 — все в памяти)"))
+	fs.StringVar(&opts.configJSON, "config-json", "", tr("конфигурация в виде JSON, например {\"block\":\"Item\",\"fields\":[{\"source\":\"Code\",\"column\":\"Код\"}]}; файл конфигурации при этом не читается"))
+	fs.StringVar(&opts.nullValues, "null-values", "", tr("значения через запятую, заменяемые пустыми, например \"—,N/A,null\" (с учётом регистра)"))
+	fs.BoolVar(&opts.withBreadcrumb, "breadcrumb", false, fmt.Sprintf(tr("добавить колонку %s с именами предков блока от корня документа"), breadcrumbColumn))
+	fs.StringVar(&opts.expectRoot, "expect-root", "", tr("пропускать XML файлы, корневой элемент которых называется иначе (локальное имя)"))
+	fs.StringVar(&opts.dateColumn, "date-column", "", tr("колонка с датой для -since и -until"))
+	fs.StringVar(&opts.since, "since", "", tr("оставить записи с датой -date-column не раньше указанной (включительно)"))
+	fs.StringVar(&opts.until, "until", "", tr("оставить записи с датой -date-column не позже указанной (включительно)"))
+	fs.BoolVar(&opts.rowNumber, "row-number", false, fmt.Sprintf(tr("добавить первой колонку %s с номером строки, начиная с 1"), rowNumberColumn))
+	fs.StringVar(&opts.traceFile, "trace", "", tr("записать в CSV файл для каждой записи путь элемента, из которого взята каждая колонка, или MISSING"))
+	fs.StringVar(&opts.warningsFile, "warnings", "", tr("дополнительно записывать предупреждения в файл JSON Lines"))
+	fs.BoolVar(&opts.blankAsEmpty, "blank-as-empty", false, tr("считать значения только из пробельных символов пустыми и без -trim"))
+	fs.StringVar(&opts.fileList, "files", "", tr("читать список XML файлов (по пути в строке) из файла или из стандартного ввода (-) вместо поиска в каталоге"))
+	fs.StringVar(&opts.dedupeNormalize, "dedupe-normalize", "", tr("сравнивать ключи -group-by, -mark-duplicates, -distinct и -id-column после преобразований через запятую: casefold (без учёта регистра), nfc (в форме Unicode NFC); значения в результате не меняются"))
+	fs.StringVar(&opts.markDups, "mark-duplicates", "", fmt.Sprintf(tr("вместо удаления повторов отметить их: keys=Колонка1,Колонка2 добавляет колонки %s (номер группы повторов) и %s (число записей с тем же ключом)"), dupGroupColumn, dupCountColumn))
+	fs.StringVar(&opts.distinct, "distinct", "", tr("вместо записей вывести отсортированные различные непустые значения одной колонки"))
+	fs.BoolVar(&opts.dropIdentical, "drop-identical-columns", false, tr("удалить колонки, значения которых во всех записях совпадают с более ранней колонкой"))
+	fs.BoolVar(&opts.explain, "explain", false, tr("вместо результата вывести для каждой записи, откуда взято значение каждой колонки (для небольших файлов)"))
+	fs.IntVar(&opts.bufferSize, "buffer-size", defaultBufferSize, tr("размер буфера записи файла результата в байтах (0 — без буфера)"))
+	fs.IntVar(&opts.rowWorkers, "row-workers", 1, tr("число потоков, формирующих строки CSV перед записью"))
+	fs.BoolVar(&opts.quoteAll, "quote-all", false, tr("заключать в кавычки все поля результата"))
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, tr("Использование: %s [флаги] [--] [каталог_с_xml] [файл_конфигурации]\n"), filepath.Base(os.Args[0]))
+		fmt.Fprintln(out, tr("Аргументы после -- считаются путями, даже если начинаются с дефиса."))
+		fmt.Fprintln(out, tr("Флаги:"))
+		fs.PrintDefaults()
+	}
+	return opts
+}
+
+func applyFlags(config *Config, opts *runOptions) error {
+	config.Trim = opts.trim
+	config.StripInvisible = opts.stripInvisible
+	config.XInclude = opts.xinclude
+	config.NoDTD = opts.noDTD
+	config.Permissive = opts.permissive
+	config.PreserveCData = opts.preserveCData
+	config.MultiDoc = opts.multiDoc
+	config.PostCommand = opts.postCommand
+	config.ReadRetries = opts.readRetries
+	var err error
+	if config.HTTP, err = newHTTPSettings(opts); err != nil {
+		return err
+	}
+	config.Translit = opts.translit
+	config.QuoteAll = opts.quoteAll
+	config.RowWorkers = opts.rowWorkers
+	config.BufferSize = opts.bufferSize
+	config.HeaderOnEmpty = opts.headerOnEmpty
+	config.AutoMap = opts.autoMap
+	config.AutoMapPrefix = opts.autoMapPrefix
+	config.AutoMapCollision = opts.autoMapCollision
+	config.FlattenAttributes = opts.flattenAttributes || opts.flattenLeafAttributes
+	config.FlattenLeafAttrs = opts.flattenLeafAttributes
+	config.ExpectRoot = opts.expectRoot
+	config.BlankAsEmpty = opts.blankAsEmpty
+	if opts.nullValues != "" {
+		config.NullValues = make(map[string]bool)
+		for _, value := range strings.Split(opts.nullValues, ",") {
+			config.NullValues[strings.TrimSpace(value)] = true
+		}
+	}
+	config.OnDuplicate = opts.onDuplicate
+	config.DuplicateSeparator = opts.duplicateSeparator
+	if opts.withSource {
+		config.WithSource = true
+		config.FieldOrder = append(config.FieldOrder, sourceColumn)
+	}
+	if opts.markDups != "" {
+		config.FieldOrder = append(config.FieldOrder, dupGroupColumn, dupCountColumn)
+	}
+	if opts.withXPath {
+		config.WithXPath = true
+		config.FieldOrder = append(config.FieldOrder, xpathColumn)
+	}
+	if opts.withBreadcrumb {
+		config.WithBreadcrumb = true
+		config.FieldOrder = append(config.FieldOrder, breadcrumbColumn)
+	}
+	config.OnMissing = opts.onMissing
+	config.MaxBlocks = opts.maxBlocks
+	config.MaxBlocksMode = opts.maxBlocksMode
+	config.UnicodeForm = opts.normalizeUnicode
+	if opts.withRaw {
+		config.WithRaw = true
+		config.FieldOrder = append(config.FieldOrder, rawColumn)
+	}
+
+	if opts.encoding != "" {
+		config.Encoding = opts.encoding
+	}
+	switch config.Encoding {
+	case "", encodingUTF8, encodingUTF8BOM, encodingCP1251, encodingUTF16LE:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестная кодировка:"), config.Encoding)
+	}
+
+	if opts.delimiter != "" {
+		config.Delimiter, _ = parseDelimiter(opts.delimiter)
+	}
+	config.CRLF = opts.eol == "crlf"
+	config.Preamble = opts.preamble
+	config.Checksum = opts.checksum
+
+	if opts.blocks != "" {
+		indices, err := parseBlockIndices(opts.blocks)
+		if err != nil {
+			return err
+		}
+		config.Blocks = indices
+	}
+
+	if opts.columns != "" {
+		for _, column := range strings.Split(opts.columns, ",") {
+			config.Columns = append(config.Columns, strings.TrimSpace(column))
+		}
+	}
+	if opts.columnsFrom != "" {
+		list, err := readColumnsFile(opts.columnsFrom)
+		if err != nil {
+			return fmt.Errorf("%s %w", tr("Ошибка при чтении списка колонок:"), err)
+		}
+		config.Columns = append(config.Columns, list...)
+	}
+
+	if opts.templateHeader != "" {
+		reader := csv.NewReader(strings.NewReader(opts.templateHeader))
+		reader.Comma = config.Delimiter
+		header, err := reader.Read()
+		if err != nil {
+			return fmt.Errorf("%s %w", tr("Ошибка в -template-header:"), err)
+		}
+		config.Columns = header
+	}
+
+	for _, definition := range opts.keyColumns {
+		key, err := parseKeyColumn(definition)
+		if err != nil {
+			return err
+		}
+		config.KeyColumns = append(config.KeyColumns, key)
+		config.FieldOrder = append(config.FieldOrder, key.Name)
+	}
+	config.KeySeparator = opts.keySeparator
+	for _, definition := range opts.moneyColumns {
+		money, err := parseMoneyColumn(definition)
+		if err != nil {
+			return err
+		}
+		config.MoneyColumns = append(config.MoneyColumns, money)
+		config.FieldOrder = append(config.FieldOrder, money.Name)
+	}
+	config.MoneyLocale = opts.moneyLocale
+	config.MoneyFormat = opts.moneyFormat
+	config.MoneyPlaces = opts.moneyPlaces
+	config.LangAttr = opts.langAttr
+	numberGroupSeparators = opts.groupSep
+	numberAutodetect = !opts.noNumberAutodetect
+	config.LangAttrName = opts.langAttrName
+	config.StrictMapping = opts.strictMapping && !config.AutoMap
+
+	for _, definition := range opts.blockFilters {
+		filter, err := parseBlockFilter(definition)
+		if err != nil {
+			return err
+		}
+		config.BlockFilters = append(config.BlockFilters, filter)
+	}
+
+	if opts.distinct != "" {
+		config.Columns = []string{opts.distinct}
+	}
+
+	if opts.rowNumber {
+		config.RowNumber = true
+		config.FieldOrder = append([]string{rowNumberColumn}, config.FieldOrder...)
+		if len(config.Columns) > 0 {
+			config.Columns = append([]string{rowNumberColumn}, config.Columns...)
+		}
+	}
+	return nil
+}
+
+func validateFlags(opts *runOptions) error {
+	if lang != "ru" && lang != "en" {
+		return fmt.Errorf("%s %s", tr("Неизвестный язык -lang:"), lang)
+	}
+	if opts.httpTimeout <= 0 {
+		return fmt.Errorf("%s %v", tr("Время -http-timeout должно быть положительным:"), opts.httpTimeout)
+	}
+	switch opts.autoMapPrefix {
+	case prefixStrip, prefixKeep:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -auto-map-prefix:"), opts.autoMapPrefix)
+	}
+	switch opts.autoMapCollision {
+	case collisionFirst, collisionSuffix, collisionError:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -auto-map-collision:"), opts.autoMapCollision)
+	}
+	switch opts.onDuplicate {
+	case duplicateFirst, duplicateLast, duplicateJoin:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -on-dup:"), opts.onDuplicate)
+	}
+	if _, err := parseKeyNormalization(opts.dedupeNormalize); err != nil {
+		return fmt.Errorf("%s %w", tr("Ошибка в -dedupe-normalize:"), err)
+	}
+	if opts.markDups != "" {
+		if _, err := parseDuplicateKeys(opts.markDups); err != nil {
+			return err
+		}
+	}
+	switch opts.onMissing {
+	case missingEmpty, missingSkipRecord, missingError:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -on-missing:"), opts.onMissing)
+	}
+	switch opts.maxBlocksMode {
+	case maxBlocksWarn, maxBlocksError:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -max-blocks-mode:"), opts.maxBlocksMode)
+	}
+	if opts.maxBlocks < 0 {
+		return fmt.Errorf("%s %d", tr("Лимит блоков не может быть отрицательным:"), opts.maxBlocks)
+	}
+	switch opts.normalizeUnicode {
+	case "", "nfc", "nfd":
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестная форма -normalize-unicode:"), opts.normalizeUnicode)
+	}
+	var delimiter rune
+	if opts.delimiter != "" {
+		d, err := parseDelimiter(opts.delimiter)
+		if err != nil {
+			return err
+		}
+		delimiter = d
+	}
+	switch opts.eol {
+	case "lf", "crlf":
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестное окончание строк:"), opts.eol)
+	}
+	switch opts.checksum {
+	case "", checksumSHA256, checksumMD5:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный алгоритм контрольной суммы:"), opts.checksum)
+	}
+	columnsSet := opts.columns != "" || opts.columnsFrom != ""
+	if opts.templateHeader != "" && columnsSet {
+		return errors.New(tr("Флаг -template-header несовместим с -columns и -columns-from"))
+	}
+	columnsSet = columnsSet || opts.templateHeader != ""
+	switch opts.moneyLocale {
+	case localePlain, localeRU, localeEN:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестная запись суммы -money-locale:"), opts.moneyLocale)
+	}
+	if opts.moneyPlaces < 0 {
+		return fmt.Errorf("%s %d", tr("Число знаков -money-places не может быть отрицательным:"), opts.moneyPlaces)
+	}
+	if strings.ContainsAny(opts.groupSep, "0123456789+-eE") {
+		return fmt.Errorf("%s %s", tr("Недопустимый разделитель разрядов -group-sep:"), opts.groupSep)
+	}
+	if opts.noNumberAutodetect && opts.groupSep != "" {
+		return errors.New(tr("Флаг -group-sep несовместим с -no-number-autodetect, задайте group= у колонок"))
+	}
+	if opts.unmappedMode != unmappedWarn && opts.unmappedMode != unmappedError {
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -unmapped-mode:"), opts.unmappedMode)
+	}
+	if opts.distinct != "" && columnsSet {
+		return errors.New(tr("Флаг -distinct несовместим с -columns, -columns-from и -template-header"))
+	}
+	if opts.rangeMode != rangeModeWarn && opts.rangeMode != rangeModeReject {
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -range-mode:"), opts.rangeMode)
+	}
+	if len(opts.sumColumns) > 0 && opts.groupBy == "" {
+		return errors.New(tr("Флаг -sum требует -group-by"))
+	}
+	if (opts.since != "" || opts.until != "") && opts.dateColumn == "" {
+		return errors.New(tr("Флаги -since и -until требуют -date-column"))
+	}
+	if opts.perFile && opts.output != "" {
+		return errors.New(tr("Флаги -per-file и -output несовместимы"))
+	}
+	switch opts.columnCollation {
+	case collationBytes, collationRU:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный порядок -column-collation:"), opts.columnCollation)
+	}
+	if opts.sortColumns && columnsSet {
+		return errors.New(tr("Флаг -sort-columns несовместим с -columns, -columns-from и -template-header"))
+	}
+	if opts.diffAgainst != "" && opts.idColumn == "" {
+		return errors.New(tr("Для -diff-against нужно указать -id-column"))
+	}
+
+	gzipOutput := opts.gzipOutput || strings.HasSuffix(strings.ToLower(opts.output), ".gz")
+	format := opts.format
+	switch format {
+	case formatCSV, formatXML, formatJSON, formatNDJSON:
+	case formatTSVExcel:
+		if opts.encoding != "" && opts.encoding != encodingUTF16LE || opts.delimiter != "" && delimiter != '\t' {
+			return errors.New(tr("Формат tsv-excel задаёт кодировку utf16le и разделитель \\t, их нельзя изменить"))
+		}
+		format = formatCSV
+	case formatParquet:
+		if gzipOutput {
+			return errors.New(tr("Формат parquet несовместим со сжатием -gzip"))
+		}
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный формат -format:"), opts.format)
+	}
+	if len(opts.alsoOutput) > 0 {
+		switch {
+		case opts.perFile:
+			return errors.New(tr("Флаг -also-output несовместим с -per-file"))
+		case opts.chunkSize != 0:
+			return errors.New(tr("Флаг -also-output несовместим с -chunk-size"))
+		case opts.zipOutput != "":
+			return errors.New(tr("Флаг -also-output несовместим с -zip-output"))
+		case opts.transpose:
+			return errors.New(tr("Флаг -also-output несовместим с -transpose"))
+		case opts.partitionAttr != "":
+			return errors.New(tr("Флаг -also-output несовместим с -partition-by-attr"))
+		}
+	}
+
+	if opts.head < 0 {
+		return fmt.Errorf("%s %d", tr("Число записей -head не может быть отрицательным:"), opts.head)
+	}
+	if opts.timingsTop < 0 {
+		return fmt.Errorf("%s %d", tr("Число файлов -timings не может быть отрицательным:"), opts.timingsTop)
+	}
+	if opts.spillThreshold < 0 {
+		return fmt.Errorf("%s %d", tr("Порог -spill-threshold не может быть отрицательным:"), opts.spillThreshold)
+	}
+	if opts.twoPass && opts.spillThreshold > 0 {
+		return errors.New(tr("Флаги -two-pass и -spill-threshold несовместимы"))
+	}
+	if err := checkStreamConflicts(opts, format); err != nil {
+		return err
+	}
+
+	switch opts.ifExists {
+	case "", ifExistsOverwrite, ifExistsSkip, ifExistsError, ifExistsRename:
+	default:
+		return fmt.Errorf("%s %s", tr("Неизвестный режим -if-exists:"), opts.ifExists)
+	}
+	if opts.maxRows < 0 {
+		return fmt.Errorf("%s %d", tr("Число записей -max-rows не может быть отрицательным:"), opts.maxRows)
+	}
+	if opts.statusFD < 0 {
+		return fmt.Errorf("%s %d", tr("Номер дескриптора -status-fd не может быть отрицательным:"), opts.statusFD)
+	}
+	if opts.withTimestamp && opts.timestampZone != "utc" && opts.timestampZone != "local" {
+		return fmt.Errorf("%s %s", tr("Неизвестный часовой пояс -timestamp-zone:"), opts.timestampZone)
+	}
+	if opts.chunkSize < 0 {
+		return fmt.Errorf("%s %d", tr("Размер части не может быть отрицательным:"), opts.chunkSize)
+	}
+	if opts.zipOutput != "" {
+		switch {
+		case gzipOutput:
+			return errors.New(tr("Флаг -zip-output несовместим с -gzip"))
+		case opts.checksum != "":
+			return errors.New(tr("Флаг -zip-output несовместим с -checksum"))
+		case opts.mergeCSV != "":
+			return errors.New(tr("Флаг -zip-output несовместим с -merge-csv"))
+		}
+	}
+	if opts.minAge < 0 {
+		return fmt.Errorf("%s %v", tr("Возраст -min-age не может быть отрицательным:"), opts.minAge)
+	}
+	if opts.skipFiles < 0 {
+		return fmt.Errorf("%s %d", tr("Число пропускаемых файлов не может быть отрицательным:"), opts.skipFiles)
+	}
+	if opts.workers < 1 {
+		return fmt.Errorf("%s %d", tr("Число обработчиков должно быть положительным:"), opts.workers)
+	}
+	if opts.bufferSize < 0 {
+		return fmt.Errorf("%s %d", tr("Размер буфера не может быть отрицательным:"), opts.bufferSize)
+	}
+	if opts.rowWorkers < 1 {
+		return fmt.Errorf("%s %d", tr("Число потоков формирования строк должно быть положительным:"), opts.rowWorkers)
+	}
+	if opts.maxOpenFiles < 1 {
+		return fmt.Errorf("%s %d", tr("Лимит открытых файлов должен быть положительным:"), opts.maxOpenFiles)
+	}
+	return nil
+}
+
+func checkStreamConflicts(opts *runOptions, format string) error {
+	var streamMode string
+	if opts.twoPass {
+		streamMode = "-two-pass"
+	} else if opts.spillThreshold > 0 {
+		streamMode = "-spill-threshold"
+	}
+	if streamMode == "" {
+		return nil
+	}
+	conflicts := map[string]bool{
+		"-group-by":               opts.groupBy != "",
+		"-sort":                   len(opts.sortKeys) > 0,
+		"-per-file":               opts.perFile,
+		"-transpose":              opts.transpose,
+		"-chunk-size":             opts.chunkSize != 0,
+		"-format " + format:       format != formatCSV,
+		"-require":                len(opts.required) > 0,
+		"-rejects":                opts.rejectsFile != "",
+		"-id-column":              opts.idColumn != "",
+		"-stats":                  opts.stats || opts.statsFile != "",
+		"-schema":                 opts.schemaFile != "",
+		"-state":                  opts.stateFile != "",
+		"-strict-rows":            opts.strictRows,
+		"-merge-csv":              opts.mergeCSV != "",
+		"-range-mode reject":      opts.rangeMode == rangeModeReject,
+		"-warn-empty-columns":     opts.warnEmptyColumns,
+		"-date-column":            opts.dateColumn != "",
+		"-drop-identical-columns": opts.dropIdentical,
+		"-distinct":               opts.distinct != "",
+		"-validate-numeric":       opts.validateNumeric != "",
+		"-diff-against":           opts.diffAgainst != "",
+		"-strict-mapping":         opts.strictMapping,
+		"-zip-output":             opts.zipOutput != "",
+		"-quality":                opts.qualityFile != "",
+		"-on-complete":            opts.onComplete != "",
+		"-webhook":                opts.webhook != "",
+		"-no-empty-output":        opts.noEmptyOutput,
+		"-trace":                  opts.traceFile != "",
+		"-block-summary":          opts.blockSummary,
+		"-sort-columns":           opts.sortColumns,
+		"-also-output":            len(opts.alsoOutput) > 0,
+		"-mark-duplicates":        opts.markDups != "",
+		"-file-totals":            opts.fileTotals != "",
+		"-partition-by-attr":      opts.partitionAttr != "",
+	}
+	var names []string
+	for name, set := range conflicts {
+		if set {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	return fmt.Errorf(tr("Флаг %s несовместим с: %s"), streamMode, strings.Join(names, ", "))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func parseTestFlags(t *testing.T, args ...string) *runOptions {
+	t.Helper()
+	fs := flag.NewFlagSet("xml_to_csv", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	opts := defineFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatalf("разбор %q: %v", args, err)
+	}
+	return opts
+}
+
+func TestValidateFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"-format", "json", "-gzip"}, ""},
+		{[]string{"-two-pass", "-output", "result.csv"}, ""},
+		{[]string{"-on-dup", "merge"}, "-on-dup"},
+		{[]string{"-on-missing", "ask"}, "-on-missing"},
+		{[]string{"-eol", "cr"}, "cr"},
+		{[]string{"-format", "xls"}, "xls"},
+		{[]string{"-format", "parquet", "-gzip"}, "-gzip"},
+		{[]string{"-sum", "Сумма"}, "-group-by"},
+		{[]string{"-since", "2024-01-01"}, "-date-column"},
+		{[]string{"-distinct", "Номер", "-columns", "Код"}, "-columns"},
+		{[]string{"-diff-against", "old.csv"}, "-id-column"},
+		{[]string{"-two-pass", "-spill-threshold", "10"}, "-spill-threshold"},
+		{[]string{"-two-pass", "-group-by", "Код"}, "-group-by"},
+		{[]string{"-zip-output", "result.zip", "-gzip"}, "-gzip"},
+		{[]string{"-no-number-autodetect", "-group-sep", " "}, "-group-sep"},
+		{[]string{"-mark-duplicates", ""}, ""},
+		{[]string{"-workers", "0"}, "0"},
+	}
+	for _, tt := range tests {
+		err := validateFlags(parseTestFlags(t, tt.args...))
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("validateFlags(%q): %v", tt.args, err)
+		case tt.want != "" && err == nil:
+			t.Errorf("validateFlags(%q) без ошибки", tt.args)
+		case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+			t.Errorf("validateFlags(%q): %v; ожидалось упоминание %q", tt.args, err, tt.want)
+		}
+	}
+}
+
+func TestCheckStreamConflicts(t *testing.T) {
+	if err := checkStreamConflicts(parseTestFlags(t, "-group-by", "Код", "-per-file"), formatCSV); err != nil {
+		t.Errorf("без потокового режима: %v", err)
+	}
+	err := checkStreamConflicts(parseTestFlags(t, "-spill-threshold", "100", "-per-file", "-group-by", "Код", "-format", "json"), formatJSON)
+	if err == nil {
+		t.Fatal("конфликт не найден")
+	}
+	if want := "-spill-threshold"; !strings.Contains(err.Error(), want) {
+		t.Errorf("ошибка %q без %q", err, want)
+	}
+	if want := "-format json, -group-by, -per-file"; !strings.Contains(err.Error(), want) {
+		t.Errorf("ошибка %q; ожидался список %q", err, want)
+	}
+}
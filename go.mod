@@ -4,5 +4,15 @@ go 1.24.2
 
 require (
 	github.com/beevik/etree v1.6.0
+	github.com/parquet-go/parquet-go v0.25.1
+	golang.org/x/sync v0.17.0
 	golang.org/x/text v0.30.0
 )
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
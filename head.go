@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const headColumnWidth = 40
+
+func headRecords(files []string, limit int, config *Config, reject func(Record) string) ([]Record, int, error) {
+	var records []Record
+	read := 0
+	for _, file := range files {
+		if len(records) >= limit {
+			break
+		}
+		read++
+		recs, err := parseXML(file, config)
+		var mismatch *rootMismatchError
+		if errors.As(err, &mismatch) {
+			warnf("unexpected-root", file, "", "%v\n", err)
+			continue
+		}
+		if err != nil {
+			return nil, read, err
+		}
+		for _, record := range recs {
+			if reject(record) == "" {
+				records = append(records, record)
+			}
+		}
+	}
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	return records, read, nil
+}
+
+func previewCell(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	return truncateValue(value, headColumnWidth, "…")
+}
+
+func writeHead(w io.Writer, records []Record, config *Config) error {
+	headers := getHeaders(records, config)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	cells := make([]string, len(headers))
+	for i, header := range headers {
+		cells[i] = previewCell(header)
+	}
+	if _, err := fmt.Fprintln(table, strings.Join(cells, "\t")); err != nil {
+		return err
+	}
+	for _, record := range records {
+		for i, header := range headers {
+			cells[i] = previewCell(record[header])
+		}
+		if _, err := fmt.Fprintln(table, strings.Join(cells, "\t")); err != nil {
+			return err
+		}
+	}
+	return table.Flush()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const hookTimeout = 30 * time.Second
+
+type RunSummary struct {
+	Outputs []string `json:"outputs"`
+	Records int      `json:"records"`
+	Blocks  int      `json:"blocks"`
+	Errors  int      `json:"errors"`
+}
+
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if isWindows {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+func notifyComplete(command, url string, summary RunSummary) {
+	if command != "" {
+		runOnComplete(command, summary)
+	}
+	if url != "" {
+		postWebhook(url, summary)
+	}
+}
+
+func runOnComplete(command string, summary RunSummary) {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(),
+		"XML_TO_CSV_OUTPUT="+strings.Join(summary.Outputs, string(os.PathListSeparator)),
+		"XML_TO_CSV_RECORDS="+strconv.Itoa(summary.Records),
+		"XML_TO_CSV_BLOCKS="+strconv.Itoa(summary.Blocks),
+		"XML_TO_CSV_ERRORS="+strconv.Itoa(summary.Errors),
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		warnf("hook-failed", "", "", "Команда -on-complete завершилась с ошибкой: %v\n", err)
+	}
+}
+
+func runPostCommand(command string, out io.Writer, write func(out io.Writer) error) error {
+	cmd := shellCommand(context.Background(), command)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при запуске -post-command: %w"), err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf(tr("ошибка при запуске -post-command: %w"), err)
+	}
+	writeErr := write(stdin)
+	closeErr := stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf(tr("команда -post-command завершилась с ошибкой: %w"), err)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if closeErr != nil {
+		return fmt.Errorf(tr("ошибка при передаче результата в -post-command: %w"), closeErr)
+	}
+	return nil
+}
+
+func postWebhook(url string, summary RunSummary) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		warnf("hook-failed", "", "", "Ошибка при отправке -webhook: %v\n", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		warnf("hook-failed", "", "", "Ошибка при отправке -webhook: %v\n", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		warnf("hook-failed", "", "", "Ошибка при отправке -webhook: %v\n", err)
+		return
+	}
+	_ = response.Body.Close()
+	if response.StatusCode >= 300 {
+		warnf("hook-failed", "", "", "Ошибка при отправке -webhook: %v\n", response.Status)
+	}
+}
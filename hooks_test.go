@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunOnCompleteReceivesSummary(t *testing.T) {
+	if isWindows {
+		t.Skip("команда записана для sh")
+	}
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2, 3))
+	writeTestFile(t, dataDir, "b.xml", "<ESADout_CU>")
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+	output := filepath.Join(outDir, "result.csv")
+	envFile := filepath.Join(outDir, "env.txt")
+
+	command := `printf '%s|%s|%s' "$XML_TO_CSV_OUTPUT" "$XML_TO_CSV_RECORDS" "$XML_TO_CSV_ERRORS" > ` + envFile
+	if code, out := runArgs(t, "-on-complete", command, "-output", output, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+
+	data, err := os.ReadFile(envFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := output + "|3|1"; string(data) != want {
+		t.Errorf("переменные окружения %q; ожидалось %q", data, want)
+	}
+}
+
+func TestRunWebhookPostsSummary(t *testing.T) {
+	received := make(chan RunSummary, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var summary RunSummary
+		if err := json.NewDecoder(r.Body).Decode(&summary); err != nil {
+			t.Error(err)
+		}
+		received <- summary
+	}))
+	defer server.Close()
+
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	config := filepath.Join(dataDir, "missing.cfg")
+	output := filepath.Join(t.TempDir(), "result.csv")
+	if code, out := runArgs(t, "-webhook", server.URL, "-output", output, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+
+	select {
+	case summary := <-received:
+		if len(summary.Outputs) != 1 || summary.Outputs[0] != output || summary.Records != 2 || summary.Errors != 0 {
+			t.Errorf("получено %+v", summary)
+		}
+	default:
+		t.Fatal("-webhook не отправлен")
+	}
+}
+
+func TestRunPostCommand(t *testing.T) {
+	if isWindows {
+		t.Skip("команды записаны для sh")
+	}
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+
+	plain := filepath.Join(outDir, "plain.csv")
+	if code, out := runArgs(t, "-output", plain, dataDir, config); code != exitOK {
+		t.Fatalf("обычный запуск: код %d\n%s", code, out)
+	}
+	piped := filepath.Join(outDir, "piped.csv")
+	if code, out := runArgs(t, "-post-command", "cat", "-output", piped, dataDir, config); code != exitOK {
+		t.Fatalf("-post-command cat: код %d\n%s", code, out)
+	}
+	want, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(piped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("-post-command cat:\n%s\nобычный запуск:\n%s", got, want)
+	}
+
+	failed := filepath.Join(outDir, "failed.csv")
+	if code, out := runArgs(t, "-post-command", "cat >/dev/null; exit 3", "-output", failed, dataDir, config); code != exitError {
+		t.Errorf("-post-command с кодом 3: код %d; ожидался %d\n%s", code, exitError, out)
+	}
+}
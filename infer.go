@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/beevik/etree"
+)
+
+type blockCandidate struct {
+	tag   string
+	count int
+	depth int
+	first int
+}
+
+func guessBlockTag(root *etree.Element) (blockCandidate, bool) {
+	candidates := make(map[string]*blockCandidate)
+	position := 0
+	var walk func(elem *etree.Element, depth int)
+	walk = func(elem *etree.Element, depth int) {
+		for _, child := range elem.ChildElements() {
+			if len(child.ChildElements()) == 0 {
+				continue
+			}
+			position++
+			candidate, ok := candidates[child.Tag]
+			if !ok {
+				candidate = &blockCandidate{tag: child.Tag, depth: depth, first: position}
+				candidates[child.Tag] = candidate
+			}
+			candidate.count++
+			candidate.depth = min(candidate.depth, depth)
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 1)
+
+	var best *blockCandidate
+	for _, candidate := range candidates {
+		switch {
+		case best == nil,
+			candidate.count > best.count,
+			candidate.count == best.count && candidate.depth < best.depth,
+			candidate.count == best.count && candidate.depth == best.depth && candidate.first < best.first:
+			best = candidate
+		}
+	}
+	if best == nil {
+		return blockCandidate{}, false
+	}
+	return *best, true
+}
+
+func inferConfig(filename string, config *Config, out io.Writer) error {
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при чтении файла %s: %w"), filename, err)
+	}
+	root := doc.Root()
+	if root == nil {
+		return fmt.Errorf(tr("в файле %s нет корневого элемента"), filename)
+	}
+	block, ok := guessBlockTag(root)
+	if !ok {
+		return errors.New(tr("не найдено элементов с вложенными элементами, блок не определить"))
+	}
+
+	fieldMap := map[string]string{parserOpenBlockTagLiteral: block.tag}
+	var fieldOrder []string
+	for _, elem := range root.FindElements("//" + block.tag) {
+		for _, sample := range sampleLeafTags(elem) {
+			if _, exists := fieldMap[sample.tag]; !exists {
+				fieldMap[sample.tag] = sample.tag
+				fieldOrder = append(fieldOrder, sample.tag)
+			}
+		}
+	}
+
+	header := fmt.Sprintf(tr("# Черновик конфигурации по файлу %s\n# Блок %s: найдено %d, полей %d. Проверьте блок и имена колонок.\n"), filename, block.tag, block.count, len(fieldOrder))
+	if _, err := io.WriteString(out, header); err != nil {
+		return err
+	}
+	return writeConfig(out, &Config{
+		FieldMap:   fieldMap,
+		FieldOrder: fieldOrder,
+		Delimiter:  config.Delimiter,
+		Encoding:   config.Encoding,
+	})
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+type sampleTag struct {
+	tag   string
+	value string
+	elem  *etree.Element
+}
+
+func sampleLeafTags(block *etree.Element) []sampleTag {
+	var tags []sampleTag
+	seen := make(map[string]bool)
+	var walk func(elem *etree.Element)
+	walk = func(elem *etree.Element) {
+		for _, child := range elem.ChildElements() {
+			if len(child.ChildElements()) > 0 {
+				walk(child)
+				continue
+			}
+			if !seen[child.Tag] {
+				seen[child.Tag] = true
+				tags = append(tags, sampleTag{tag: child.Tag, value: strings.TrimSpace(child.Text()), elem: child})
+			}
+		}
+	}
+	walk(block)
+	return tags
+}
+
+func interactiveConfig(filename string, config *Config, in io.Reader) error {
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при чтении файла %s: %w"), filename, err)
+	}
+	blockTag := config.FieldMap[parserOpenBlockTagLiteral]
+	blocks, err := findBlocks(doc, blockTag)
+	if err != nil {
+		return err
+	}
+	if len(blocks) == 0 {
+		return fmt.Errorf(tr("в файле %s нет блоков %s"), filename, blockTag)
+	}
+
+	reader := bufio.NewReader(in)
+	ask := func(prompt string) (string, error) {
+		fmt.Print(prompt)
+		line, err := reader.ReadString('\n')
+		if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	fmt.Printf(tr("Поля блока %s в файле %s. Введите имя колонки или нажмите Enter, чтобы пропустить поле.\n"), blockTag, filename)
+	fieldMap := map[string]string{parserOpenBlockTagLiteral: blockTag}
+	var fieldOrder []string
+	for _, sample := range sampleLeafTags(blocks[0]) {
+		column, err := ask(fmt.Sprintf(tr("%s (пример: %q): "), sample.tag, sample.value))
+		if err != nil {
+			return fmt.Errorf(tr("ошибка при чтении ответа: %w"), err)
+		}
+		if column == "" {
+			continue
+		}
+		fieldMap[sample.tag] = column
+		fieldOrder = append(fieldOrder, column)
+	}
+	if len(fieldOrder) == 0 {
+		return errors.New(tr("не выбрано ни одного поля"))
+	}
+	config.FieldMap = fieldMap
+	config.FieldOrder = fieldOrder
+	config.BlockTypes = nil
+
+	target, err := ask(tr("Сохранить конфигурацию в файл (Enter — не сохранять): "))
+	if err != nil || target == "" {
+		return nil
+	}
+	if _, err := os.Stat(target); !errors.Is(err, fs.ErrNotExist) {
+		answer, err := ask(fmt.Sprintf(tr("Файл %s уже существует, перезаписать? [y/N]: "), target))
+		if err != nil || !strings.EqualFold(answer, "y") {
+			fmt.Println(tr("Конфигурация не сохранена"))
+			return nil
+		}
+	}
+	file, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при сохранении конфигурации: %w"), err)
+	}
+	if err := writeConfig(file, config); err != nil {
+		return fmt.Errorf(tr("ошибка при сохранении конфигурации: %w"), errors.Join(err, file.Close()))
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf(tr("ошибка при сохранении конфигурации: %w"), err)
+	}
+	fmt.Println(tr("Конфигурация сохранена в"), target)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInteractiveConfigScriptedAnswers(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeTestFile(t, dir, "d.xml", `<ESADout_CU><ESADout_CUGoods>
+	<GoodsNumeric>1</GoodsNumeric>
+	<GoodsDescription>Болт</GoodsDescription>
+	<Weight><Gross>2.5</Gross></Weight>
+</ESADout_CUGoods></ESADout_CU>`)
+	target := filepath.Join(dir, "saved.cfg")
+	config := loadTestConfig(t, filepath.Join(dir, "missing.cfg"), false)
+
+	answers := strings.NewReader("Номер\n\nБрутто\n" + target + "\n")
+	if err := interactiveConfig(filename, config, answers); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(config.FieldOrder, ",") != "Номер,Брутто" {
+		t.Errorf("FieldOrder = %q; ожидалось [Номер Брутто]", config.FieldOrder)
+	}
+	if _, exists := config.FieldMap["GoodsDescription"]; exists {
+		t.Error("пропущенное поле GoodsDescription осталось в конфигурации")
+	}
+
+	saved := loadTestConfig(t, target, true)
+	if saved.FieldMap["Gross"] != "Брутто" || saved.FieldMap[parserOpenBlockTagLiteral] != "ESADout_CUGoods" {
+		t.Errorf("сохранённая конфигурация: %v", saved.FieldMap)
+	}
+
+	config = loadTestConfig(t, filepath.Join(dir, "missing.cfg"), false)
+	if err := interactiveConfig(filename, config, strings.NewReader("\n\n\n")); err == nil {
+		t.Error("без выбранных полей ошибка не возвращена")
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func jsonValue(value string, numeric bool, options FieldOptions, invalid map[string]int, header string) any {
+	if !numeric {
+		return value
+	}
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	normalized, ok := normalizeNumber(value, options)
+	if !ok || !json.Valid([]byte(normalized)) || strings.HasPrefix(normalized, "+") {
+		invalid[header]++
+		return value
+	}
+	return json.Number(normalized)
+}
+
+func writeJSONObject(w *bufio.Writer, record Record, headers []string, numeric map[string]bool, options map[string]FieldOptions, invalid map[string]int) error {
+	w.WriteByte('{')
+	first := true
+	for _, header := range headers {
+		value, ok := record[header]
+		if !ok {
+			continue
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		key, err := json.Marshal(header)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(jsonValue(value, numeric[header], options[header], invalid, header))
+		if err != nil {
+			return err
+		}
+		w.Write(key)
+		w.WriteByte(':')
+		w.Write(data)
+	}
+	return w.WriteByte('}')
+}
+
+func writeJSON(filename string, records []Record, config *Config) error {
+	return createOutput(filename, config, func(out io.Writer) error {
+		lineEnd := "\n"
+		if config.CRLF {
+			lineEnd = "\r\n"
+		}
+		headers := getHeaders(records, config)
+		numeric := make(map[string]bool)
+		if !config.NumbersAsStrings {
+			for _, header := range headers {
+				numeric[header] = numericField(config.FieldOptions[header])
+			}
+		}
+		invalid := make(map[string]int)
+
+		w := bufio.NewWriter(out)
+		if config.Metadata != nil {
+			metadata, err := json.Marshal(config.Metadata)
+			if err != nil {
+				return err
+			}
+			if config.Format == formatJSON {
+				w.WriteString(`{"metadata":` + string(metadata) + `,"records":`)
+			} else {
+				w.WriteString(`{"metadata":` + string(metadata) + "}" + lineEnd)
+			}
+		}
+		if config.Format == formatJSON {
+			w.WriteString("[" + lineEnd)
+		}
+		for i, record := range records {
+			if err := writeJSONObject(w, record, headers, numeric, config.FieldOptions, invalid); err != nil {
+				return fmt.Errorf(tr("ошибка при записи строки: %w"), err)
+			}
+			if config.Format == formatJSON && i < len(records)-1 {
+				w.WriteByte(',')
+			}
+			w.WriteString(lineEnd)
+		}
+		if config.Format == formatJSON && config.Metadata != nil {
+			w.WriteString("]}" + lineEnd)
+		} else if config.Format == formatJSON {
+			w.WriteString("]" + lineEnd)
+		}
+		for _, header := range headers {
+			if invalid[header] > 0 {
+				warnf("invalid-number", filename, header, "В колонке %q записано строкой нечисловых значений: %d\n", header, invalid[header])
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf(tr("ошибка при записи JSON файла: %w"), err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+var reportedLookupMisses sync.Map
+
+func loadLookups(config *Config) error {
+	tables := make(map[string]map[string]string)
+	config.Lookups = make(map[string]map[string]string)
+	for field, options := range config.FieldOptions {
+		filename := options["lookup"]
+		if filename == "" {
+			continue
+		}
+		if _, loaded := tables[filename]; !loaded {
+			table, err := readLookup(filename, config.Delimiter)
+			if err != nil {
+				return fmt.Errorf(tr("ошибка при чтении таблицы замен %s: %w"), filename, err)
+			}
+			tables[filename] = table
+		}
+		config.Lookups[field] = tables[filename]
+	}
+	return nil
+}
+
+func readLookup(filename string, delimiter rune) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	reader := csv.NewReader(file)
+	reader.Comma = delimiter
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	table := make(map[string]string)
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return table, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && len(row) > 0 {
+			row[0] = strings.TrimPrefix(row[0], "\uFEFF")
+		}
+		if len(row) < 2 {
+			return nil, fmt.Errorf(tr("строка %d: ожидается две колонки, получено %d"), line, len(row))
+		}
+		table[strings.TrimSpace(row[0])] = row[1]
+	}
+}
+
+func applyLookup(record Record, field string, table map[string]string) {
+	value, ok := record[field]
+	if !ok || value == "" {
+		return
+	}
+	if replacement, found := table[value]; found {
+		record[field] = replacement
+		return
+	}
+	if _, reported := reportedLookupMisses.LoadOrStore(field+"\x00"+value, true); !reported {
+		warnf("lookup-miss", "", field, "Значение %q колонки %q не найдено в таблице замен\n", value, field)
+	}
+}
@@ -1,34 +1,329 @@
 package main
 
 import (
+	"archive/zip"
 	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
 	"encoding/csv"
+	"errors"
+	"flag"
 	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/beevik/etree"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/text/collate"
 	"golang.org/x/text/encoding/charmap"
+	unicodeenc "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/language"
+	"golang.org/x/text/unicode/norm"
 )
 
-const parserOpenBlockTagLiteral = "parser_open_block_tag"
+const (
+	parserOpenBlockTagLiteral = "parser_open_block_tag"
+	parserCSVDelimiterLiteral = "parser_csv_delimiter"
+	parserCSVEncodingLiteral  = "parser_csv_encoding"
+	skipIfPrefix              = "skip-if:"
+	countPrefix               = "count:"
+	xpathColumn               = "__xpath"
+	breadcrumbColumn          = "__breadcrumb"
+	sourceColumn              = "__source"
+	dupGroupColumn            = "__dup_group"
+	dupCountColumn            = "__dup_count"
+	rowNumberColumn           = "№"
+	convertedAtColumn         = "__converted_at"
+	rawColumn                 = "__raw"
+	blockTypeColumn           = "__block_type"
+	rejectReasonColumn        = "__reason"
+	transposeFieldColumn      = "Поле"
+)
+
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitNoFiles     = 2
+	exitNoRecords   = 3
+	exitAllFiltered = 4
+)
+
+const (
+	defaultTimestampFormat = "2006-01-02_15-04-05"
+	defaultMaxOpenFiles    = 128
+	readRetryBackoff       = 100 * time.Millisecond
+	defaultBufferSize      = 256 << 10
+)
+
+const (
+	duplicateFirst = "first"
+	duplicateLast  = "last"
+	duplicateJoin  = "join"
+)
+
+const (
+	orderName      = "name"
+	orderMtime     = "mtime"
+	orderMtimeDesc = "mtime-desc"
+)
+
+const (
+	overflowTruncate = "truncate"
+	overflowError    = "error"
+)
+
+const (
+	prefixStrip = "strip"
+	prefixKeep  = "keep"
+)
+
+const (
+	collisionFirst  = "first"
+	collisionSuffix = "suffix"
+	collisionError  = "error"
+)
+
+const (
+	roundNearest = "nearest"
+	roundFloor   = "floor"
+	roundCeil    = "ceil"
+)
+
+const (
+	collationBytes = "bytes"
+	collationRU    = "ru"
+)
+
+const (
+	localePlain = "plain"
+	localeRU    = "ru"
+	localeEN    = "en"
+)
+
+const (
+	emitText    = "text"
+	emitTagName = "tagname"
+)
+
+const (
+	missingEmpty      = "empty"
+	missingSkipRecord = "skip-record"
+	missingError      = "error"
+)
+
+const (
+	checksumSHA256 = "sha256"
+	checksumMD5    = "md5"
+)
+
+const (
+	maxBlocksWarn  = "warn"
+	maxBlocksError = "error"
+)
+
+const (
+	unmappedWarn  = "warn"
+	unmappedError = "error"
+)
+
+const (
+	ifExistsOverwrite = "overwrite"
+	ifExistsSkip      = "skip"
+	ifExistsError     = "error"
+	ifExistsRename    = "rename"
+)
+
+const (
+	formatCSV      = "csv"
+	formatXML      = "xml"
+	formatJSON     = "json"
+	formatNDJSON   = "ndjson"
+	formatParquet  = "parquet"
+	formatTSVExcel = "tsv-excel"
+)
+
+const (
+	encodingUTF8    = "utf8"
+	encodingUTF8BOM = "utf8-bom"
+	encodingCP1251  = "cp1251"
+	encodingUTF16LE = "utf16le"
+)
 
 var (
+	knownFieldOptions = map[string]bool{
+		"type":          true,
+		"translit":      true,
+		"split-into":    true,
+		"on":            true,
+		"extra":         true,
+		"min":           true,
+		"max":           true,
+		"lookup":        true,
+		"round":         true,
+		"trim-prefix":   true,
+		"trim-suffix":   true,
+		"trim-left":     true,
+		"trim-right":    true,
+		"unwrap":        true,
+		"trim":          true,
+		"max-depth":     true,
+		"maxlen":        true,
+		"on-overflow":   true,
+		"ellipsis":      true,
+		"on-missing":    true,
+		"emit":          true,
+		"join-newlines": true,
+		"default":       true,
+		"excel-text":    true,
+		"decimal":       true,
+		"group":         true,
+	}
+
 	isWindows = strings.Contains(strings.ToLower(runtime.GOOS), "windows")
+
+	openFiles = make(chan struct{}, defaultMaxOpenFiles)
+	verbose   = false
+	noPause   = false
+
+	invisibleReplacer = strings.NewReplacer(
+		"\u200B", "",
+		"\u200C", "",
+		"\u200D", "",
+		"\u2060", "",
+		"\uFEFF", "",
+	)
 )
 
 type Config struct {
-	FieldOrder []string
-	FieldMap   map[string]string
+	FieldOrder         []string
+	FieldMap           map[string]string
+	FieldOptions       map[string]FieldOptions
+	SkipRules          []SkipRule
+	BlockFilters       []BlockFilter
+	KeyColumns         []KeyColumn
+	StrictMapping      bool
+	Zip                *zip.Writer
+	LangAttr           string
+	LangAttrName       string
+	KeySeparator       string
+	MoneyColumns       []MoneyColumn
+	MoneyFormat        string
+	MoneyLocale        string
+	MoneyPlaces        int
+	BlockTypes         []*BlockDefinition
+	DocumentFields     []DocumentField
+	Nested             []*NestedDefinition
+	WithXPath          bool
+	WithSource         bool
+	WithBreadcrumb     bool
+	RowNumber          bool
+	ConvertedAt        string
+	WithRaw            bool
+	Encoding           string
+	Delimiter          rune
+	Trim               bool
+	StripInvisible     bool
+	UnicodeForm        string
+	XInclude           bool
+	NoDTD              bool
+	Permissive         bool
+	PreserveCData      bool
+	MultiDoc           bool
+	DefaultMapping     bool
+	HTTP               *HTTPSettings
+	PostCommand        string
+	PartitionAttr      string
+	MaxRows            int
+	Metadata           *RunMetadata
+	Trace              bool
+	Gzip               bool
+	CRLF               bool
+	Preamble           []string
+	ReadRetries        int
+	OnDuplicate        string
+	DuplicateSeparator string
+	Blocks             []int
+	MaxBlocks          int
+	MaxBlocksMode      string
+	OnMissing          string
+	Checksum           string
+	Columns            []string
+	Translit           bool
+	Lookups            map[string]map[string]string
+	QuoteAll           bool
+	RowWorkers         int
+	BufferSize         int
+	HeaderOnEmpty      bool
+	NumbersAsStrings   bool
+	AutoMap            bool
+	AutoMapPrefix      string
+	AutoMapCollision   string
+	FlattenAttributes  bool
+	FlattenLeafAttrs   bool
+	NullValues         map[string]bool
+	ExpectRoot         string
+	BlankAsEmpty       bool
+	Format             string
+}
+
+type FieldOptions map[string]string
+
+type BlockDefinition struct {
+	Name      string
+	FieldMap  map[string]string
+	SkipRules []SkipRule
+	Nested    []*NestedDefinition
+}
+
+type SkipRule struct {
+	Tag   string
+	Value string
+}
+
+type KeyColumn struct {
+	Name  string
+	Parts []string
+}
+
+type MoneyColumn struct {
+	Name     string
+	Amount   string
+	Currency string
+}
+
+type BlockFilter struct {
+	Attr  string
+	Value string
 }
 
 type Record map[string]string
 
-func loadConfig(configFile string) *Config {
+type outputSet struct {
+	filename string
+	records  []Record
+}
+
+type Rejection struct {
+	Record Record
+	Reason string
+}
+
+func loadConfig(configFile string, inline io.Reader, noDefaults bool) (*Config, error) {
 	fieldOrder := []string{
 		"Номер",
 		"Название",
@@ -64,208 +359,2686 @@ func loadConfig(configFile string) *Config {
 		"PrDocumentNumber":         "Инвойс",
 	}
 
+	if noDefaults {
+		fieldOrder = nil
+		fieldMap = map[string]string{}
+	}
+
 	config := &Config{
-		FieldOrder: fieldOrder,
-		FieldMap:   fieldMap,
+		FieldOrder:   fieldOrder,
+		FieldMap:     fieldMap,
+		FieldOptions: make(map[string]FieldOptions),
+		Delimiter:    ';',
 	}
 
 	if configFile == "" {
 		configFile = ".xml_to_csv_cfg"
 	}
 
-	if file, err := os.Open(configFile); err == nil {
+	if inline != nil {
+		if err := readConfigLines(config, inline, "."); err != nil {
+			return nil, fmt.Errorf(tr("ошибка при чтении -config-json: %w"), err)
+		}
+	} else if info, err := os.Stat(configFile); err == nil && info.IsDir() {
+		return nil, fmt.Errorf(tr("путь к файлу конфигурации %s — каталог, а не файл"), configFile)
+	} else if file, err := os.Open(configFile); err == nil {
 		defer func() { _ = file.Close() }()
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" || strings.HasPrefix(line, "#") {
+		if err := readConfigLines(config, file, filepath.Dir(configFile)); err != nil {
+			return nil, fmt.Errorf(tr("ошибка при чтении файла конфигурации %s: %w"), configFile, err)
+		}
+	} else {
+		config.DefaultMapping = !noDefaults
+	}
+
+	nestedDefinitions := config.Nested
+	for _, block := range config.BlockTypes {
+		nestedDefinitions = append(nestedDefinitions, block.Nested...)
+	}
+	for _, nested := range nestedDefinitions {
+		for _, column := range nested.columns() {
+			if !slices.Contains(config.FieldOrder, column) {
+				config.FieldOrder = append(config.FieldOrder, column)
+			}
+		}
+	}
+	if len(config.BlockTypes) > 0 && !slices.Contains(config.FieldOrder, blockTypeColumn) {
+		config.FieldOrder = append(config.FieldOrder, blockTypeColumn)
+	}
+	addSplitColumns(config)
+	return config, nil
+}
+
+func checkFieldOptions(fieldOptions map[string]FieldOptions) error {
+	for field, options := range fieldOptions {
+		if err := checkNumberFormat(options); err != nil {
+			return fmt.Errorf(tr("Недопустимый формат числа колонки %q: %v"), field, err)
+		}
+		if !numberAutodetect && numericField(options) && options["decimal"] == "" {
+			return fmt.Errorf(tr("С -no-number-autodetect колонке %q с type=%s нужен параметр decimal="), field, options["type"])
+		}
+		if options["type"] == "int" {
+			switch options["round"] {
+			case "", roundNearest, roundFloor, roundCeil:
+			default:
+				return fmt.Errorf(tr("Недопустимое значение round=%q для колонки %q с type=int: ожидается nearest, floor или ceil"), options["round"], field)
+			}
+		} else if options["round"] != "" {
+			if places, err := strconv.Atoi(options["round"]); err != nil || places < 0 {
+				return fmt.Errorf(tr("Недопустимое значение round=%q для колонки %q"), options["round"], field)
+			}
+			if options["type"] != "number" {
+				fmt.Printf(tr("Параметр round колонки %q действует только вместе с type=number\n"), field)
+			}
+		}
+		if options["max-depth"] != "" {
+			if depth, err := strconv.Atoi(options["max-depth"]); err != nil || depth < 1 {
+				return fmt.Errorf(tr("Недопустимое значение max-depth=%q для колонки %q"), options["max-depth"], field)
+			}
+		}
+		if options["maxlen"] != "" {
+			limit, err := strconv.Atoi(options["maxlen"])
+			if err != nil || limit < 1 {
+				return fmt.Errorf(tr("Недопустимое значение maxlen=%q для колонки %q"), options["maxlen"], field)
+			}
+			switch options["on-overflow"] {
+			case "", overflowTruncate, overflowError:
+			default:
+				return fmt.Errorf(tr("Недопустимое значение on-overflow=%q для колонки %q: ожидается truncate или error"), options["on-overflow"], field)
+			}
+			if utf8.RuneCountInString(options["ellipsis"]) >= limit {
+				return fmt.Errorf(tr("Параметр ellipsis колонки %q не короче maxlen=%d"), field, limit)
+			}
+		}
+		switch options["on-missing"] {
+		case "", missingEmpty, missingSkipRecord, missingError:
+		default:
+			return fmt.Errorf(tr("Недопустимое значение on-missing=%q для колонки %q: ожидается empty, skip-record или error"), options["on-missing"], field)
+		}
+		switch options["emit"] {
+		case "", emitText, emitTagName:
+		default:
+			return fmt.Errorf(tr("Недопустимое значение emit=%q для колонки %q: ожидается text или tagname"), options["emit"], field)
+		}
+		switch options["excel-text"] {
+		case "", "true", "false":
+		default:
+			return fmt.Errorf(tr("Недопустимое значение excel-text=%q для колонки %q: ожидается true или false"), options["excel-text"], field)
+		}
+		if _, err := optionText(options["join-newlines"]); err != nil {
+			return fmt.Errorf(tr("Недопустимое значение join-newlines=%s для колонки %q: %v"), options["join-newlines"], field, err)
+		}
+	}
+	return nil
+}
+
+func readConfigLines(config *Config, r io.Reader, baseDir string) error {
+	fieldMap, skipRules, nestedList := config.FieldMap, &config.SkipRules, &config.Nested
+	var nested *NestedDefinition
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			if strings.HasPrefix(name, nestedSectionPrefix) {
+				definition, err := parseNestedHeader(name)
+				if err != nil {
+					fmt.Printf(tr("Ошибка в строке %q: %v\n"), line, err)
+					definition = &NestedDefinition{FieldMap: make(map[string]string)}
+				} else {
+					*nestedList = append(*nestedList, definition)
+				}
+				nested = definition
+				continue
+			}
+			block := &BlockDefinition{
+				Name:     name,
+				FieldMap: make(map[string]string),
+			}
+			config.BlockTypes = append(config.BlockTypes, block)
+			fieldMap, skipRules, nestedList = block.FieldMap, &block.SkipRules, &block.Nested
+			nested = nil
+			continue
+		}
+		if nested != nil {
+			xmlTag, csvField, found := strings.Cut(line, "=")
+			if !found {
 				continue
 			}
-			parts := strings.Split(line, "=")
+			xmlTag, csvField = strings.TrimSpace(xmlTag), strings.TrimSpace(csvField)
+			if xmlTag != parserOpenBlockTagLiteral {
+				var options FieldOptions
+				if csvField, options = parseFieldOptions(csvField); len(options) > 0 {
+					fmt.Printf(tr("Параметры полей во вложенном разделе не поддерживаются: %q\n"), line)
+				}
+				if !slices.Contains(nested.Fields, csvField) {
+					nested.Fields = append(nested.Fields, csvField)
+				}
+			}
+			nested.FieldMap[xmlTag] = csvField
+			continue
+		}
+		if strings.HasPrefix(line, skipIfPrefix) {
+			parts := strings.SplitN(strings.TrimPrefix(line, skipIfPrefix), "=", 2)
 			if len(parts) == 2 {
-				xmlTag := strings.TrimSpace(parts[0])
-				csvField := strings.TrimSpace(parts[1])
-				config.FieldMap[xmlTag] = csvField
-				found := false
-				for _, field := range config.FieldOrder {
-					if field == csvField {
-						found = true
-						break
+				*skipRules = append(*skipRules, SkipRule{
+					Tag:   strings.TrimSpace(parts[0]),
+					Value: strings.TrimSpace(parts[1]),
+				})
+			}
+			continue
+		}
+		if strings.HasPrefix(line, piPrefix) || strings.HasPrefix(line, commentPrefix) {
+			field, err := parseDocumentField(line)
+			if err != nil {
+				fmt.Printf(tr("Ошибка в строке %q: %v\n"), line, err)
+				continue
+			}
+			config.DocumentFields = append(config.DocumentFields, field)
+			if !slices.Contains(config.FieldOrder, field.Column) {
+				config.FieldOrder = append(config.FieldOrder, field.Column)
+			}
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) == 2 {
+			xmlTag := strings.TrimSpace(parts[0])
+			csvField := strings.TrimSpace(parts[1])
+			switch xmlTag {
+			case parserCSVDelimiterLiteral:
+				if delimiter, err := parseDelimiter(csvField); err == nil {
+					config.Delimiter = delimiter
+				}
+				continue
+			case parserCSVEncodingLiteral:
+				config.Encoding = csvField
+				continue
+			}
+			if xmlTag != parserOpenBlockTagLiteral {
+				var options FieldOptions
+				csvField, options = parseFieldOptions(csvField)
+				for key, value := range options {
+					if !knownFieldOptions[key] {
+						fmt.Printf(tr("Неизвестный параметр поля %q в строке %q\n"), key, line)
+						continue
+					}
+					if key == "lookup" && !filepath.IsAbs(value) {
+						value = filepath.Join(baseDir, value)
 					}
+					if config.FieldOptions[csvField] == nil {
+						config.FieldOptions[csvField] = make(FieldOptions)
+					}
+					config.FieldOptions[csvField][key] = value
 				}
-				if !found {
-					config.FieldOrder = append(config.FieldOrder, csvField)
+			}
+			fieldMap[xmlTag] = csvField
+			if xmlTag == parserOpenBlockTagLiteral {
+				continue
+			}
+			found := false
+			for _, field := range config.FieldOrder {
+				if field == csvField {
+					found = true
+					break
 				}
 			}
+			if !found {
+				config.FieldOrder = append(config.FieldOrder, csvField)
+			}
 		}
 	}
-
-	return config
+	return scanner.Err()
 }
 
-func main() {
-	if isWindows {
-		defer func() {
-			fmt.Println("Нажмите Enter для выхода...")
-			_, _ = fmt.Scanln()
-		}()
+func addSplitColumns(config *Config) {
+	for _, csvField := range append([]string(nil), config.FieldOrder...) {
+		names := config.FieldOptions[csvField]["split-into"]
+		if names == "" {
+			continue
+		}
+		position := slices.Index(config.FieldOrder, csvField) + 1
+		for _, name := range strings.Split(names, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || slices.Contains(config.FieldOrder, name) {
+				continue
+			}
+			config.FieldOrder = slices.Insert(config.FieldOrder, position, name)
+			position++
+		}
 	}
+}
 
-	var dataDir, configFile string
-
-	if len(os.Args) > 1 {
-		dataDir = os.Args[1]
-	} else {
-		dataDir = "data"
+func splitField(record Record, csvField string, options FieldOptions, trim bool) {
+	value, exists := record[csvField]
+	if !exists {
+		return
 	}
-
-	if len(os.Args) > 2 {
-		configFile = os.Args[2]
-	} else {
-		configFile = "xml_to_csv_cfg"
+	separator := options["on"]
+	if separator == "" {
+		separator = "/"
 	}
 
-	config := loadConfig(configFile)
-
-	files, err := filepath.Glob(filepath.Join(dataDir, "*.[xX][mM][lL]"))
-	if err != nil {
-		fmt.Println("Ошибка при поиске XML файлов:", err)
+	var names []string
+	for _, name := range strings.Split(options["split-into"], ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
 		return
 	}
 
-	wg := &sync.WaitGroup{}
-	mu := &sync.Mutex{}
-	var records []Record
-
-	for _, file := range files {
-		wg.Add(1)
-		go func(f string) {
-			defer wg.Done()
-			recs := parseXML(f, config)
-			if len(recs) > 0 {
-				mu.Lock()
-				records = append(records, recs...)
-				mu.Unlock()
-			}
-		}(file)
+	parts := strings.Split(value, separator)
+	if len(parts) > len(names) && options["extra"] == "append" {
+		parts[len(names)-1] = strings.Join(parts[len(names)-1:], separator)
 	}
+	for i, name := range names {
+		part := ""
+		if i < len(parts) {
+			part = parts[i]
+		}
+		if trim {
+			part = strings.TrimSpace(part)
+		}
+		record[name] = part
+	}
+}
 
-	done := make(chan bool)
-	go func() {
-		wg.Wait()
-		done <- true
-	}()
-
-	select {
-	case <-done:
-	case <-time.After(2 * time.Minute):
-		fmt.Println("Таймаут")
-		return
+func parseDelimiter(value string) (rune, error) {
+	if value == `\t` {
+		return '\t', nil
 	}
+	runes := []rune(value)
+	if len(runes) != 1 || runes[0] == '"' || runes[0] == '\r' || runes[0] == '\n' {
+		return 0, fmt.Errorf(tr("недопустимый разделитель: %q"), value)
+	}
+	return runes[0], nil
+}
 
-	if len(records) > 0 {
-		writeCSV(records, config)
-	} else {
-		fmt.Println("Нет данных... завершение программы")
+func parseFieldOptions(value string) (string, FieldOptions) {
+	parts := strings.Split(value, ";")
+	options := make(FieldOptions)
+	for _, part := range parts[1:] {
+		key, optionValue, _ := strings.Cut(part, "=")
+		if key = strings.TrimSpace(key); key != "" {
+			options[key] = strings.TrimSpace(optionValue)
+		}
 	}
+	return strings.TrimSpace(parts[0]), options
 }
 
-func parseXML(filename string, config *Config) []Record {
-	doc := etree.NewDocument()
-	if err := doc.ReadFromFile(filename); err != nil {
-		return nil
+func (o FieldOptions) String() string {
+	keys := make([]string, 0, len(o))
+	for key := range o {
+		keys = append(keys, key)
 	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, key := range keys {
+		b.WriteString(";" + key + "=" + o[key])
+	}
+	return b.String()
+}
 
-	blockTag, exists := config.FieldMap[parserOpenBlockTagLiteral]
-	if !exists {
-		return nil
+func writeConfig(w io.Writer, config *Config) error {
+	var lines []string
+	if blockTag, exists := config.FieldMap[parserOpenBlockTagLiteral]; exists {
+		lines = append(lines, parserOpenBlockTagLiteral+"="+blockTag)
+	}
+	delimiter := string(config.Delimiter)
+	if config.Delimiter == '\t' {
+		delimiter = `\t`
+	}
+	lines = append(lines, parserCSVDelimiterLiteral+"="+delimiter)
+	if config.Encoding != "" {
+		lines = append(lines, parserCSVEncodingLiteral+"="+config.Encoding)
+	}
+	lines = append(lines, mappingLines(config.FieldMap, config.SkipRules, config)...)
+	for _, field := range config.DocumentFields {
+		lines = append(lines, field.String())
+	}
+	for _, nested := range config.Nested {
+		lines = append(lines, "")
+		lines = append(lines, nested.lines()...)
+	}
+	for _, block := range config.BlockTypes {
+		lines = append(lines, "", "["+block.Name+"]")
+		if blockTag, exists := block.FieldMap[parserOpenBlockTagLiteral]; exists {
+			lines = append(lines, parserOpenBlockTagLiteral+"="+blockTag)
+		}
+		lines = append(lines, mappingLines(block.FieldMap, block.SkipRules, config)...)
+		for _, nested := range block.Nested {
+			lines = append(lines, "")
+			lines = append(lines, nested.lines()...)
+		}
 	}
 
-	var records []Record
-	for _, block := range doc.FindElements("//" + blockTag) {
-		record := make(Record)
-		for xmlTag, csvField := range config.FieldMap {
-			if xmlTag == parserOpenBlockTagLiteral {
-				continue
-			}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
 
-			elem := block.FindElement(".//" + xmlTag)
-			if elem != nil {
-				if elem.Text() == "ContractCurrencyCode" {
-					fmt.Println("1")
-				}
-				record[csvField] = elem.Text()
-			}
+func mappingLines(fieldMap map[string]string, skipRules []SkipRule, config *Config) []string {
+	var lines []string
+	for _, rule := range skipRules {
+		lines = append(lines, skipIfPrefix+rule.Tag+"="+rule.Value)
+	}
+
+	xmlTags := make([]string, 0, len(fieldMap))
+	for xmlTag := range fieldMap {
+		if xmlTag != parserOpenBlockTagLiteral {
+			xmlTags = append(xmlTags, xmlTag)
 		}
-		if len(record) > 0 {
-			records = append(records, record)
+	}
+	sort.Strings(xmlTags)
+	for _, csvField := range config.FieldOrder {
+		for _, xmlTag := range xmlTags {
+			if fieldMap[xmlTag] == csvField {
+				lines = append(lines, xmlTag+"="+csvField+config.FieldOptions[csvField].String())
+			}
 		}
 	}
-	return records
+	return lines
 }
 
-func writeCSV(records []Record, config *Config) {
-	timestamp := time.Now().Format("2006-01-02_15-04-05")
-	filename := fmt.Sprintf("result_%s.csv", timestamp)
+func main() {
+	code := run()
+	finishStatus(code)
+	if isWindows && !noPause && isTerminal(os.Stdin) {
+		fmt.Println(tr("Нажмите Enter для выхода..."))
+		_, _ = fmt.Scanln()
+	}
+	os.Exit(code)
+}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		fmt.Println("Ошибка при создании CSV файла:", err)
-		return
+func run() int {
+	lang = langFromArgs(os.Args[1:])
+	opts := defineFlags(flag.CommandLine)
+	flag.Parse()
+	if err := validateFlags(opts); err != nil {
+		fmt.Println(err)
+		return exitError
 	}
-	defer func() { _ = file.Close() }()
 
-	var writer *csv.Writer
-	if isWindows {
-		encoder := charmap.Windows1251.NewEncoder()
-		writer = csv.NewWriter(encoder.Writer(file))
+	var dataDir, configFile string
+
+	if flag.NArg() > 0 {
+		dataDir = flag.Arg(0)
 	} else {
-		writer = csv.NewWriter(file)
+		dataDir = besideExecutable("data")
 	}
-	writer.Comma = ';'
-	defer writer.Flush()
 
-	if len(records) == 0 {
-		return
+	if flag.NArg() > 1 {
+		configFile = flag.Arg(1)
+	} else {
+		configFile = besideExecutable("xml_to_csv_cfg")
 	}
 
-	headers := getHeaders(records, config)
-	if err := writer.Write(headers); err != nil {
-		fmt.Println("Ошибка при записи заголовков:", err)
-		return
+	var inlineConfig io.Reader
+	if opts.configJSON != "" {
+		lines, err := configJSONLines(opts.configJSON)
+		if err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		inlineConfig = strings.NewReader(lines)
 	}
+	config, err := loadConfig(configFile, inlineConfig, opts.noDefaults)
+	if err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+	if err := applyFlags(config, opts); err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+	keyNormalize, _ := parseKeyNormalization(opts.dedupeNormalize)
+	dupKeys, _ := parseDuplicateKeys(opts.markDups)
 
-	for _, record := range records {
-		row := make([]string, len(headers))
-		for i, header := range headers {
-			row[i] = record[header]
+	if opts.printConfig {
+		if err := writeConfig(os.Stdout, config); err != nil {
+			fmt.Println(tr("Ошибка при выводе конфигурации:"), err)
+			return exitError
 		}
-		if err := writer.Write(row); err != nil {
-			fmt.Println("Ошибка при записи строки:", err)
-			return
+		return exitOK
+	}
+	if opts.validateSample != "" {
+		missing, err := validateConfigReferences(opts.validateSample, config)
+		if err != nil {
+			fmt.Println(err)
+			return exitError
 		}
+		if missing > 0 {
+			return exitError
+		}
+		return exitOK
+	}
+	if opts.inferSample != "" {
+		if err := inferConfig(opts.inferSample, config, os.Stdout); err != nil {
+			fmt.Println(tr("Ошибка при подборе конфигурации:"), err)
+			return exitError
+		}
+		return exitOK
 	}
-}
 
-func getHeaders(records []Record, config *Config) []string {
-	var headers []string
-	usedFields := make(map[string]bool)
+	if _, exists := config.FieldMap[parserOpenBlockTagLiteral]; !exists {
+		fmt.Println(tr("В конфигурации не задан"), parserOpenBlockTagLiteral)
+		return exitError
+	}
+	for _, block := range config.BlockTypes {
+		if _, exists := block.FieldMap[parserOpenBlockTagLiteral]; !exists {
+			fmt.Printf(tr("В разделе [%s] конфигурации не задан %s\n"), block.Name, parserOpenBlockTagLiteral)
+			return exitError
+		}
+	}
+	nestedDefinitions := config.Nested
+	for _, block := range config.BlockTypes {
+		nestedDefinitions = append(nestedDefinitions, block.Nested...)
+	}
+	for _, nested := range nestedDefinitions {
+		if _, exists := nested.FieldMap[parserOpenBlockTagLiteral]; !exists {
+			fmt.Printf(tr("В разделе %s конфигурации не задан %s\n"), nested.header(), parserOpenBlockTagLiteral)
+			return exitError
+		}
+	}
 
-	for _, csvField := range config.FieldOrder {
-		if !usedFields[csvField] {
-			headers = append(headers, csvField)
-			usedFields[csvField] = true
+	if err := checkFieldOptions(config.FieldOptions); err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+	if err := loadLookups(config); err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+
+	var sinceTime, untilTime time.Time
+	if opts.since != "" {
+		var err error
+		if sinceTime, err = parseDateBound(opts.since, false); err != nil {
+			fmt.Println(tr("Ошибка в -since:"), err)
+			return exitError
+		}
+	}
+	if opts.until != "" {
+		var err error
+		if untilTime, err = parseDateBound(opts.until, true); err != nil {
+			fmt.Println(tr("Ошибка в -until:"), err)
+			return exitError
+		}
+	}
+	switch opts.format {
+	case formatCSV, formatXML:
+		config.Format = opts.format
+	case formatTSVExcel:
+		config.Format = formatCSV
+		config.Encoding = encodingUTF16LE
+		config.Delimiter = '\t'
+		config.CRLF = true
+	case formatJSON, formatNDJSON:
+		if config.Encoding != "" && config.Encoding != encodingUTF8 {
+			fmt.Printf(tr("Формат %s записывается только в кодировке utf8\n"), opts.format)
+			return exitError
 		}
+		config.Format = opts.format
+		config.Encoding = encodingUTF8
+		config.NumbersAsStrings = opts.numbersAsStrings
+	case formatParquet:
+		if config.Encoding != "" && config.Encoding != encodingUTF8 {
+			fmt.Printf(tr("Формат %s записывается только в кодировке utf8\n"), opts.format)
+			return exitError
+		}
+		config.Format = opts.format
+		config.Encoding = encodingUTF8
 	}
 
-	for _, record := range records {
-		for key := range record {
-			if !usedFields[key] {
-				headers = append(headers, key)
-				usedFields[key] = true
+	sinks, err := parseSinks(opts.alsoOutput)
+	if err != nil {
+		fmt.Println(tr("Ошибка в -also-output:"), err)
+		return exitError
+	}
+	if len(sinks) > 0 {
+		config.NumbersAsStrings = opts.numbersAsStrings
+	}
+	parseTimings.enabled = opts.timingsTop > 0 || opts.timingsFile != ""
+
+	if opts.ifExists == "" {
+		opts.ifExists = ifExistsOverwrite
+		if opts.output != "" {
+			opts.ifExists = ifExistsError
+		}
+	}
+	if opts.warningsFile != "" {
+		if err := openWarningLog(opts.warningsFile); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		defer func() {
+			if err := closeWarningLog(); err != nil {
+				fmt.Println(err)
 			}
+		}()
+	}
+	config.MaxRows = opts.maxRows
+	if opts.statusFD > 0 {
+		if err := openStatus(opts.statusFD); err != nil {
+			fmt.Println(err)
+			return exitError
 		}
 	}
+	if opts.traceFile != "" {
+		if err := openTraceLog(opts.traceFile, config.Delimiter); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		config.Trace = true
+		defer func() {
+			if err := closeTraceLog(); err != nil {
+				fmt.Println(err)
+			}
+		}()
+	}
 
-	return headers
+	now := time.Now()
+	if opts.withTimestamp {
+		config.ConvertedAt = now.UTC().Format(time.RFC3339)
+		if opts.timestampZone == "local" {
+			config.ConvertedAt = now.Format(time.RFC3339)
+		}
+		config.FieldOrder = append(config.FieldOrder, convertedAtColumn)
+	}
+
+	filename, err := outputFilename(opts.output, opts.timestampFormat, "."+config.Format, now)
+	if err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+	config.Gzip = opts.gzipOutput || strings.HasSuffix(strings.ToLower(filename), ".gz")
+	if config.Gzip && opts.output == "" {
+		filename += ".gz"
+	}
+	if opts.outDir != "" {
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(opts.outDir, filename)
+		}
+		if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+			fmt.Println(tr("Ошибка при создании каталога результата:"), err)
+			return exitError
+		}
+	}
+
+	switch {
+	case opts.head > 0:
+	case opts.zipOutput != "":
+		var write bool
+		if opts.zipOutput, write, err = resolveExisting(opts.zipOutput, opts.ifExists); err != nil {
+			fmt.Println(err)
+			return exitError
+		} else if !write {
+			return exitOK
+		}
+	case !opts.perFile && opts.partitionAttr == "" && (opts.chunkSize == 0 || opts.mergeCSV != ""):
+		var write bool
+		if filename, write, err = resolveExisting(filename, opts.ifExists); err != nil {
+			fmt.Println(err)
+			return exitError
+		} else if !write {
+			return exitOK
+		}
+		for i := range sinks {
+			if sinks[i].filename == filename {
+				fmt.Printf(tr("Файл %s указан и в -output, и в -also-output\n"), filename)
+				return exitError
+			}
+			if sinks[i].filename, write, err = resolveExisting(sinks[i].filename, opts.ifExists); err != nil {
+				fmt.Println(err)
+				return exitError
+			} else if !write {
+				return exitOK
+			}
+		}
+	}
+
+	if opts.mergeCSV != "" {
+		var delimiter rune
+		if opts.mergeDelimiter != "auto" {
+			if delimiter, err = parseDelimiter(opts.mergeDelimiter); err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+		}
+		if err := mergeCSVFiles(strings.Split(opts.mergeCSV, ","), filename, config, delimiter); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	var files []string
+	if opts.fileList != "" {
+		if files, err = readFileList(opts.fileList); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		if len(files) == 0 {
+			fmt.Println(tr("Список файлов -files пуст"))
+			return exitNoFiles
+		}
+	} else {
+		pattern := "*.[xX][mM][lL]"
+		seen := make(map[string]bool)
+		for _, dir := range strings.Split(dataDir, ",") {
+			if isURL(dir) {
+				if !seen[dir] {
+					seen[dir] = true
+					files = append(files, dir)
+				}
+				continue
+			}
+			found, err := filepath.Glob(filepath.Join(dir, pattern))
+			if err != nil {
+				fmt.Println(tr("Ошибка при поиске XML файлов:"), err)
+				return exitError
+			}
+			if len(found) == 0 {
+				verbosef("В каталоге %q не найдено файлов по шаблону %q\n", dir, pattern)
+			}
+			sort.Strings(found)
+			for _, file := range found {
+				if !seen[file] {
+					seen[file] = true
+					files = append(files, file)
+				}
+			}
+		}
+		if len(files) == 0 {
+			fmt.Printf(tr("В каталоге %q не найдено файлов по шаблону %q\n"), dataDir, pattern)
+			return exitNoFiles
+		}
+	}
+	if slices.ContainsFunc(files, isURL) {
+		localOnly := map[string]bool{
+			"-min-age":  opts.minAge > 0,
+			"-order":    opts.fileOrder == orderMtime || opts.fileOrder == orderMtimeDesc,
+			"-state":    opts.stateFile != "",
+			"-xinclude": config.XInclude,
+		}
+		for _, name := range []string{"-min-age", "-order", "-state", "-xinclude"} {
+			if localOnly[name] {
+				fmt.Printf(tr("Флаг %s несовместим с загрузкой XML по адресу http:// или https://\n"), name)
+				return exitError
+			}
+		}
+	}
+	if opts.minAge > 0 {
+		now := time.Now()
+		ready := files[:0]
+		for _, file := range files {
+			info, err := os.Stat(file)
+			if err != nil {
+				fmt.Println(tr("Ошибка при чтении сведений о файле:"), err)
+				return exitError
+			}
+			if age := now.Sub(info.ModTime()); age < opts.minAge {
+				warnf("too-recent", file, "", "Файл %s изменён %v назад, пропущен (-min-age %v)\n", file, age.Round(time.Second), opts.minAge)
+				continue
+			}
+			ready = append(ready, file)
+		}
+		if len(ready) == 0 {
+			fmt.Printf(tr("Все файлы изменены менее %v назад\n"), opts.minAge)
+			return exitNoFiles
+		}
+		files = ready
+	}
+	if opts.fileOrder != "" {
+		if err := sortFiles(files, opts.fileOrder); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+	if opts.skipFiles > 0 {
+		if opts.skipFiles >= len(files) {
+			fmt.Printf(tr("Все файлы пропущены: -skip-files %d, найдено файлов: %d\n"), opts.skipFiles, len(files))
+			return exitNoFiles
+		}
+		verbosef("Пропущены файлы: %s\n", strings.Join(files[:opts.skipFiles], ", "))
+		files = files[opts.skipFiles:]
+	}
+	if config.DefaultMapping {
+		checkDefaultMapping(files[0], configFile, config)
+	}
+
+	var state *RunState
+	var fileStates map[string]FileState
+	if opts.stateFile != "" {
+		if state, err = loadState(opts.stateFile); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		var changed []string
+		if changed, fileStates, err = changedFiles(files, state); err != nil {
+			fmt.Println(tr("Ошибка при чтении сведений о файле:"), err)
+			return exitError
+		}
+		if len(changed) == 0 {
+			fmt.Println(tr("Новых или изменённых файлов нет"))
+			return exitOK
+		}
+		fmt.Printf(tr("Файлов к обработке: %d из %d\n"), len(changed), len(files))
+		files = changed
+	}
+
+	openFiles = make(chan struct{}, opts.maxOpenFiles)
+
+	if opts.embeddedMapping != "" {
+		added, err := applyEmbeddedMapping(files[0], opts.embeddedMapping, config)
+		if err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		fmt.Printf(tr("Добавлено сопоставлений из файла %s: %d\n"), files[0], added)
+		if err := loadLookups(config); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+
+	if opts.interactive {
+		if !isTerminal(os.Stdin) {
+			fmt.Println(tr("Режим -interactive доступен только при вводе с терминала"))
+			return exitError
+		}
+		if err := interactiveConfig(files[0], config, os.Stdin); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+
+	reject := func(record Record) string {
+		recs, rejections := requireColumns([]Record{record}, opts.required, make(map[string]int), nil)
+		if len(recs) > 0 {
+			recs, rejections = checkRanges(recs, config, opts.rangeMode, rejections)
+		}
+		if len(recs) > 0 && opts.dateColumn != "" {
+			_, rejections = filterDates(recs, opts.dateColumn, sinceTime, untilTime, rejections)
+		}
+		if len(rejections) > 0 {
+			return rejections[0].Reason
+		}
+		return ""
+	}
+	if opts.explain {
+		for _, file := range files {
+			if err := explainFile(file, config, reject, os.Stdout); err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+		}
+		return exitOK
+	}
+
+	if opts.head > 0 {
+		recs, read, err := headRecords(files, opts.head, config, reject)
+		if err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		if config.AutoMap || config.FlattenAttributes {
+			appendAutoColumns(config, recs)
+		}
+		fmt.Printf(tr("Первые записи: %d (прочитано файлов %d из %d)\n"), len(recs), read, len(files))
+		if err := writeHead(os.Stdout, recs, config); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		return exitOK
+	}
+	config.PartitionAttr = strings.TrimSpace(opts.partitionAttr)
+
+	if opts.embedMetadata {
+		input := dataDir
+		if opts.fileList != "" {
+			input = opts.fileList
+		}
+		if config.Metadata, err = newRunMetadata(input, len(files), config, now); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		if config.Format == formatCSV {
+			config.Preamble = append(config.Preamble, config.Metadata.lines()...)
+		}
+	}
+	statusStart(len(files))
+	if opts.twoPass || opts.spillThreshold > 0 {
+		summary, err := streamOutput(ctx, files, filename, config, opts.failFast, opts.spillThreshold)
+		if err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		if parseTimings.enabled {
+			if err := reportTimings(opts.timingsTop, opts.timingsFile, config); err != nil {
+				fmt.Println(tr("Ошибка при записи времени разбора:"), err)
+				return exitError
+			}
+		}
+		statusSummary(summary)
+		return exitOK
+	}
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(opts.workers)
+
+	results := make([][]Record, len(files))
+	failed := make([]bool, len(files))
+	skipped := make([]bool, len(files))
+
+	done := make(chan error, 1)
+	go func() {
+		for i, file := range files {
+			group.Go(func() error {
+				if err := groupCtx.Err(); err != nil {
+					return err
+				}
+				recs, err := parseXML(file, config)
+				var mismatch *rootMismatchError
+				if errors.As(err, &mismatch) {
+					warnf("unexpected-root", file, "", "%v\n", err)
+					statusFile(file, statusSkipped, 0)
+					skipped[i] = true
+					return nil
+				}
+				if err != nil {
+					statusFile(file, statusError, 0)
+					if opts.failFast {
+						return err
+					}
+					warnf("file-error", file, "", "%v\n", err)
+					failed[i] = true
+					return nil
+				}
+				statusFile(file, statusOK, len(recs))
+				results[i] = recs
+				return nil
+			})
+		}
+		done <- group.Wait()
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	case <-ctx.Done():
+		fmt.Println(tr("Таймаут"))
+		return exitError
+	}
+
+	var partitions [][]string
+	if config.PartitionAttr != "" {
+		partitions = takePartitions(results)
+	}
+	if config.AutoMap || config.FlattenAttributes {
+		var all []Record
+		for _, recs := range results {
+			all = append(all, recs...)
+		}
+		appendAutoColumns(config, all)
+	}
+
+	skippedCount := 0
+	for _, skip := range skipped {
+		if skip {
+			skippedCount++
+		}
+	}
+	if skippedCount > 0 {
+		fmt.Printf(tr("Пропущено файлов с другим корневым элементом: %d из %d\n"), skippedCount, len(files))
+	}
+
+	if config.StrictMapping {
+		if unmapped := reportUnmapped(); unmapped > 0 && opts.unmappedMode == unmappedError {
+			return exitError
+		}
+	}
+
+	if totalsColumns := parseTotalsColumns(opts.fileTotals); len(totalsColumns) > 0 {
+		reportName := opts.fileTotalsOutput
+		if reportName == "" {
+			reportName = siblingReportName(filename, "_totals.csv")
+		}
+		excluded := make([]bool, len(files))
+		for i := range files {
+			excluded[i] = failed[i] || skipped[i]
+		}
+		if err := writeFileTotals(reportName, files, results, excluded, totalsColumns, config); err != nil {
+			fmt.Println(tr("Ошибка при записи итогов по файлам:"), err)
+			return exitError
+		}
+	}
+
+	var sets []outputSet
+	if opts.perFile {
+		sets, err = perFileSets(files, results, partitions, opts.outDir, config)
+	} else {
+		sets, err = combinedSets(filename, results, partitions)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+
+	var rejected []Rejection
+	dropped := make(map[string]int)
+	dateDropped := 0
+	for i := range sets {
+		if len(opts.required) > 0 {
+			sets[i].records, rejected = requireColumns(sets[i].records, opts.required, dropped, rejected)
+		}
+		if opts.dateColumn != "" {
+			before := len(sets[i].records)
+			sets[i].records, rejected = filterDates(sets[i].records, opts.dateColumn, sinceTime, untilTime, rejected)
+			dateDropped += before - len(sets[i].records)
+		}
+		sets[i].records, rejected = checkRanges(sets[i].records, config, opts.rangeMode, rejected)
+		if opts.groupBy != "" {
+			sets[i].records = groupRecords(sets[i].records, opts.groupBy, opts.sumColumns, keyNormalize, config.FieldOptions)
+		}
+		if len(dupKeys) > 0 {
+			groups := markDuplicates(sets[i].records, dupKeys, keyNormalize)
+			fmt.Printf(tr("Групп повторов по колонкам %s в %s: %d\n"), strings.Join(dupKeys, ", "), sets[i].filename, groups)
+		}
+		if len(opts.sortKeys) > 0 {
+			sortRecords(sets[i].records, parseSortKeys(opts.sortKeys), config)
+		}
+		if opts.distinct != "" {
+			sets[i].records = distinctValues(sets[i].records, opts.distinct, config, keyNormalize)
+		}
+	}
+	for _, column := range opts.required {
+		if dropped[column] > 0 {
+			warnf("opts.required-missing", "", column, "Отброшено записей без значения в колонке %q: %d\n", column, dropped[column])
+		}
+	}
+
+	if dateDropped > 0 {
+		warnf("date-filtered", "", opts.dateColumn, "Отброшено записей вне периода или без даты в колонке %q: %d\n", opts.dateColumn, dateDropped)
+	}
+
+	if opts.rejectsFile != "" {
+		if err := writeRejects(opts.rejectsFile, rejected, config); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+
+	var records []Record
+	for _, set := range sets {
+		records = append(records, set.records...)
+	}
+
+	if opts.strictRows {
+		if err := checkStrictRows(records, config); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+	if err := checkMaxRows(len(records), config.MaxRows); err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+
+	if opts.idColumn != "" {
+		if duplicates := checkUniqueIDs(records, opts.idColumn, keyNormalize); duplicates > 0 && opts.idStrict {
+			return exitError
+		}
+	}
+
+	if opts.diffAgainst != "" {
+		reportName := opts.diffOutput
+		if reportName == "" {
+			reportName = siblingReportName(filename, "_diff.csv")
+		}
+		if err := writeDiff(reportName, opts.diffAgainst, opts.idColumn, records, config); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+
+	if opts.validateNumeric != "" {
+		var numericColumns []string
+		for _, column := range strings.Split(opts.validateNumeric, ",") {
+			numericColumns = append(numericColumns, strings.TrimSpace(column))
+		}
+		invalid := 0
+		for _, set := range sets {
+			invalid += checkNumericColumns(set.filename, set.records, numericColumns, config.FieldOptions)
+		}
+		if invalid > 0 {
+			fmt.Printf(tr("Нечисловых значений: %d\n"), invalid)
+			if opts.numericStrict {
+				return exitError
+			}
+		}
+	}
+
+	if opts.dropIdentical && len(records) > 0 && len(config.Columns) == 0 {
+		if dropped := dropIdenticalColumns(records, config); len(dropped) > 0 {
+			fmt.Printf(tr("Удалено одинаковых колонок: %d\n"), len(dropped))
+		}
+	}
+
+	code := exitOK
+	if len(records) == 0 {
+		extracted := 0
+		for _, recs := range results {
+			extracted += len(recs)
+		}
+		if extracted > 0 {
+			fmt.Printf(tr("Все записи отброшены при отборе: %d\n"), extracted)
+		}
+		if opts.noEmptyOutput {
+			code = exitNoRecords
+			if extracted > 0 {
+				code = exitAllFiltered
+			}
+		}
+	}
+
+	if len(records) == 0 && config.HeaderOnEmpty && code == exitOK {
+		fmt.Println(tr("Нет данных, записывается только заголовок"))
+	}
+	var summary RunSummary
+	if code != exitOK {
+		fmt.Println(tr("Нет данных, файл результата не создаётся"))
+	} else if len(records) > 0 || config.HeaderOnEmpty {
+		if config.RowNumber {
+			for _, set := range sets {
+				for j, record := range set.records {
+					record[rowNumberColumn] = strconv.Itoa(j + 1)
+				}
+			}
+		}
+		reportUnknownColumns(records, config.Columns)
+		if opts.warnEmptyColumns {
+			reportEmptyColumns(records, config, opts.emptyThreshold)
+		}
+		outConfig := config
+		if opts.lockSchema {
+			locked := *config
+			locked.FieldOrder = getHeaders(records, config)
+			if opts.sortColumns {
+				sortHeaders(locked.FieldOrder, opts.columnCollation, config.RowNumber)
+			}
+			outConfig = &locked
+		}
+		var archive *os.File
+		var written []string
+		entries := make(map[string]bool)
+		if opts.zipOutput != "" {
+			if archive, err = os.Create(opts.zipOutput); err != nil {
+				fmt.Println(tr("Ошибка при создании архива результата:"), err)
+				return exitError
+			}
+			defer func() { _ = archive.Close() }()
+			zipConfig := *outConfig
+			zipConfig.Zip = zip.NewWriter(archive)
+			outConfig = &zipConfig
+		}
+		for _, set := range sets {
+			if len(set.records) == 0 && (!config.HeaderOnEmpty || opts.noEmptyOutput) {
+				continue
+			}
+			setConfig := outConfig
+			if opts.sortColumns && !opts.lockSchema {
+				sorted := *outConfig
+				sorted.FieldOrder = getHeaders(set.records, outConfig)
+				sortHeaders(sorted.FieldOrder, opts.columnCollation, config.RowNumber)
+				setConfig = &sorted
+			}
+			if opts.transpose {
+				if len(set.records) > opts.transposeLimit {
+					warnf("transpose-limit", set.filename, "", "Записей больше %d, %s записан без транспонирования\n", opts.transposeLimit, set.filename)
+				} else {
+					set.records, setConfig = transposeRecords(set.records, setConfig)
+				}
+			}
+			for _, chunk := range chunkRecords(set, opts.chunkSize) {
+				if archive != nil {
+					name := filepath.Base(chunk.filename)
+					if entries[name] {
+						fmt.Printf(tr("Файл %s уже есть в архиве %s\n"), name, opts.zipOutput)
+						return exitError
+					}
+					entries[name] = true
+				} else if opts.perFile || opts.chunkSize > 0 || opts.partitionAttr != "" {
+					var write bool
+					if chunk.filename, write, err = resolveExisting(chunk.filename, opts.ifExists); err != nil {
+						fmt.Println(err)
+						return exitError
+					} else if !write {
+						continue
+					}
+				}
+				if err := writeOutput(chunk.filename, chunk.records, setConfig); err != nil {
+					fmt.Println(err)
+					return exitError
+				}
+				if archive == nil {
+					written = append(written, chunk.filename)
+				}
+			}
+		}
+		if archive != nil {
+			if err := outConfig.Zip.Close(); err != nil {
+				fmt.Println(tr("Ошибка при записи архива результата:"), err)
+				return exitError
+			}
+			if err := archive.Close(); err != nil {
+				fmt.Println(tr("Ошибка при записи архива результата:"), err)
+				return exitError
+			}
+			fmt.Printf(tr("Записан архив %s, файлов: %d\n"), opts.zipOutput, len(entries))
+			written = append(written, opts.zipOutput)
+		}
+		if len(sinks) > 0 {
+			sinkBase := outConfig
+			if opts.sortColumns && !opts.lockSchema {
+				sorted := *outConfig
+				sorted.FieldOrder = getHeaders(records, outConfig)
+				sortHeaders(sorted.FieldOrder, opts.columnCollation, config.RowNumber)
+				sinkBase = &sorted
+			}
+			sinkFiles, err := writeSinks(sinks, records, sinkBase)
+			written = append(written, sinkFiles...)
+			if err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+		}
+		blocks, _ := blockTotals()
+		summary = RunSummary{Outputs: written, Records: len(records), Blocks: blocks}
+		if opts.stats || opts.statsFile != "" {
+			if err := printStats(opts.statsFile, records, config); err != nil {
+				fmt.Println(tr("Ошибка при записи статистики:"), err)
+				return exitError
+			}
+		}
+		if opts.qualityFile != "" {
+			if err := writeQuality(opts.qualityFile, records, config); err != nil {
+				fmt.Println(tr("Ошибка при записи показателей качества:"), err)
+				return exitError
+			}
+		}
+		if opts.schemaFile != "" {
+			if err := writeSchema(opts.schemaFile, records, config); err != nil {
+				fmt.Println(tr("Ошибка при записи описания колонок:"), err)
+				return exitError
+			}
+		}
+	} else {
+		fmt.Println(tr("Нет данных... завершение программы"))
+	}
+
+	if state != nil {
+		for i, file := range files {
+			if failed[i] || skipped[i] {
+				continue
+			}
+			if err := state.record(file, fileStates[file]); err != nil {
+				fmt.Println(err)
+				return exitError
+			}
+		}
+		if err := saveState(opts.stateFile, state); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+	}
+
+	if parseTimings.enabled {
+		if err := reportTimings(opts.timingsTop, opts.timingsFile, config); err != nil {
+			fmt.Println(tr("Ошибка при записи времени разбора:"), err)
+			return exitError
+		}
+	}
+	if opts.blockSummary {
+		reportBlocks(files, config.FieldMap[parserOpenBlockTagLiteral], summary.Records)
+	}
+
+	if len(summary.Outputs) > 0 {
+		for _, fail := range failed {
+			if fail {
+				summary.Errors++
+			}
+		}
+		notifyComplete(opts.onComplete, opts.webhook, summary)
+	}
+	statusSummary(summary)
+	return code
+}
+
+type rootMismatchError struct {
+	filename string
+	root     string
+	expected string
+}
+
+func (e *rootMismatchError) Error() string {
+	return fmt.Sprintf(tr("Файл %s пропущен: корневой элемент %q, ожидается %q"), e.filename, e.root, e.expected)
+}
+
+func readFileList(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf(tr("ошибка при чтении списка файлов: %w"), err)
+		}
+		defer func() { _ = file.Close() }()
+		r = file
+	}
+
+	var files []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf(tr("ошибка при чтении списка файлов: %w"), err)
+	}
+	return files, nil
+}
+
+func sortFiles(files []string, order string) error {
+	if order == orderName {
+		sort.Strings(files)
+		return nil
+	}
+	if order != orderMtime && order != orderMtimeDesc {
+		return fmt.Errorf(tr("неизвестный порядок -order: %q (ожидается name, mtime или mtime-desc)"), order)
+	}
+	modified := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return fmt.Errorf(tr("ошибка при чтении файла %s: %w"), file, err)
+		}
+		modified[file] = info.ModTime()
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		a, b := modified[files[i]], modified[files[j]]
+		if a.Equal(b) {
+			return files[i] < files[j]
+		}
+		if order == orderMtimeDesc {
+			return a.After(b)
+		}
+		return a.Before(b)
+	})
+	return nil
+}
+
+func parseXML(filename string, config *Config) ([]Record, error) {
+	if parseTimings.enabled {
+		defer recordTiming(filename, time.Now())
+	}
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return nil, fmt.Errorf(tr("ошибка при чтении файла %s: %w"), filename, err)
+	}
+	if config.NoDTD && hasDoctype(doc) {
+		return nil, fmt.Errorf(tr("файл %s содержит DTD, обработка запрещена флагом -no-dtd"), filename)
+	}
+	if config.ExpectRoot != "" {
+		roots := doc.ChildElements()
+		if len(roots) == 0 {
+			return nil, &rootMismatchError{filename: filename, expected: config.ExpectRoot}
+		}
+		for _, root := range roots {
+			if root.Tag != config.ExpectRoot {
+				return nil, &rootMismatchError{filename: filename, root: root.Tag, expected: config.ExpectRoot}
+			}
+		}
+	}
+	if config.XInclude {
+		if err := expandIncludes(doc, filename, config, 0); err != nil {
+			return nil, fmt.Errorf(tr("ошибка в файле %s: %w"), filename, err)
+		}
+	} else {
+		includes := 0
+		for _, root := range doc.ChildElements() {
+			includes += len(findIncludes(root))
+		}
+		if includes > 0 {
+			warnf("xinclude-skipped", filename, "", "Файл %s содержит включения xi:include (%d), они пропущены; используйте -xinclude\n", filename, includes)
+		}
+	}
+
+	records, err := extractRecords(doc, filename, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(config.BlockTypes) > 0 {
+		for _, record := range records {
+			record[blockTypeColumn] = config.FieldMap[parserOpenBlockTagLiteral]
+		}
+	}
+	for _, block := range config.BlockTypes {
+		blockConfig := *config
+		blockConfig.FieldMap = block.FieldMap
+		blockConfig.SkipRules = block.SkipRules
+		blockConfig.Nested = block.Nested
+		blockRecords, err := extractRecords(doc, filename, &blockConfig)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range blockRecords {
+			record[blockTypeColumn] = block.Name
+		}
+		records = append(records, blockRecords...)
+	}
+	if len(config.DocumentFields) > 0 {
+		values := documentValues(doc, config.DocumentFields)
+		for _, record := range records {
+			for column, value := range values {
+				record[column] = value
+			}
+		}
+	}
+	for _, record := range records {
+		addKeyColumns(record, config)
+		addMoneyColumns(record, config)
+	}
+	countRecords(filename, len(records))
+	return records, nil
+}
+
+func parseMoneyColumn(definition string) (MoneyColumn, error) {
+	name, parts, ok := strings.Cut(definition, "=")
+	amount, currency, found := strings.Cut(parts, "+")
+	money := MoneyColumn{Name: strings.TrimSpace(name), Amount: strings.TrimSpace(amount), Currency: strings.TrimSpace(currency)}
+	if !ok || !found || money.Name == "" || money.Amount == "" || money.Currency == "" {
+		return MoneyColumn{}, fmt.Errorf("%s %s", tr("Недопустимый -money-column, ожидается ИМЯ=КОЛОНКА_СУММЫ+КОЛОНКА_ВАЛЮТЫ:"), definition)
+	}
+	return money, nil
+}
+
+func addMoneyColumns(record Record, config *Config) {
+	for _, money := range config.MoneyColumns {
+		amount := record[money.Amount]
+		if strings.TrimSpace(amount) == "" {
+			record[money.Name] = ""
+			continue
+		}
+		if formatted, ok := formatAmount(amount, config.MoneyPlaces, config.MoneyLocale, config.FieldOptions[money.Amount]); ok {
+			amount = formatted
+		}
+		value := strings.NewReplacer("{amount}", amount, "{currency}", record[money.Currency]).Replace(config.MoneyFormat)
+		record[money.Name] = strings.TrimSpace(value)
+	}
+}
+
+func parseKeyColumn(definition string) (KeyColumn, error) {
+	name, parts, ok := strings.Cut(definition, "=")
+	key := KeyColumn{Name: strings.TrimSpace(name)}
+	for _, part := range strings.Split(parts, "+") {
+		if part = strings.TrimSpace(part); part != "" {
+			key.Parts = append(key.Parts, part)
+		}
+	}
+	if !ok || key.Name == "" || len(key.Parts) == 0 {
+		return KeyColumn{}, fmt.Errorf("%s %s", tr("Недопустимый -key-column, ожидается ИМЯ=КОЛОНКА1+КОЛОНКА2:"), definition)
+	}
+	return key, nil
+}
+
+func addKeyColumns(record Record, config *Config) {
+	for _, key := range config.KeyColumns {
+		parts := make([]string, len(key.Parts))
+		filled := false
+		for i, column := range key.Parts {
+			parts[i] = record[column]
+			filled = filled || parts[i] != ""
+		}
+		if filled {
+			record[key.Name] = strings.Join(parts, config.KeySeparator)
+		} else {
+			record[key.Name] = ""
+		}
+	}
+}
+
+func extractRecords(doc *etree.Document, filename string, config *Config) ([]Record, error) {
+	blockTag, exists := config.FieldMap[parserOpenBlockTagLiteral]
+	if !exists {
+		return nil, nil
+	}
+
+	mappings, countFields := compileMappings(config)
+	tags, paths := mappingSources(mappings)
+
+	blocks, err := findBlocks(doc, blockTag)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxBlocks > 0 && len(blocks) > config.MaxBlocks {
+		if config.MaxBlocksMode == maxBlocksError {
+			return nil, fmt.Errorf(tr("в файле %s блоков %s больше допустимого: %d (лимит %d)"), filename, blockTag, len(blocks), config.MaxBlocks)
+		}
+		warnf("max-blocks", filename, "", "В файле %s блоков %s больше допустимого: %d (лимит %d)\n", filename, blockTag, len(blocks), config.MaxBlocks)
+	}
+	countBlocks(filename, len(blocks))
+	if len(config.Blocks) > 0 {
+		blocks = selectBlocks(blocks, config.Blocks, filename)
+	}
+
+	nestedDropped := make(map[string]int)
+	truncated := make(map[string]int)
+	missing := make(map[string]int)
+	notIntegers := make(map[string]int)
+	var records []Record
+blocks:
+	for _, block := range blocks {
+		if !matchesFilters(block, config.BlockFilters) || shouldSkip(block, config.SkipRules) {
+			continue
+		}
+		elements := gatherElements(block, tags, paths)
+		if config.StrictMapping {
+			collectUnmapped(block, elements, countFields, config)
+		}
+
+		record := make(Record)
+		var traced map[string]string
+		if config.Trace {
+			traced = make(map[string]string)
+		}
+		for _, mapping := range mappings {
+			if _, found := record[mapping.csvField]; found {
+				continue
+			}
+			value, matched, ok := mapping.match(block, elements, config)
+			if ok {
+				record[mapping.csvField] = value
+			}
+			if config.Trace {
+				if ok {
+					traced[mapping.csvField] = strings.Join(matched.paths(), ", ")
+				} else if traced[mapping.csvField] == "" {
+					traced[mapping.csvField] = traceMissing
+				}
+			}
+		}
+		for _, mapping := range mappings {
+			if _, found := record[mapping.csvField]; found {
+				continue
+			}
+			if value, ok := config.FieldOptions[mapping.csvField]["default"]; ok {
+				record[mapping.csvField] = value
+				continue
+			}
+			policy := config.FieldOptions[mapping.csvField]["on-missing"]
+			if policy == "" {
+				policy = config.OnMissing
+			}
+			switch policy {
+			case missingError:
+				return nil, fmt.Errorf(tr("в файле %s в блоке %s не найден элемент колонки %q"), filename, elementPath(block), mapping.csvField)
+			case missingSkipRecord:
+				missing[mapping.csvField]++
+				continue blocks
+			}
+		}
+		for _, nested := range config.Nested {
+			nestedDropped[nested.Prefix] += nested.extract(block, record, config)
+		}
+		if config.AutoMap {
+			leaves, err := autoMapLeaves(block, config)
+			if err != nil {
+				return nil, fmt.Errorf(tr("в файле %s в блоке %s: %w"), filename, elementPath(block), err)
+			}
+			for _, leaf := range leaves {
+				if _, found := record[leaf.tag]; !found && !tags[leaf.elem.Tag] {
+					record[leaf.tag] = leaf.elem.Text()
+				}
+			}
+		}
+		if config.FlattenAttributes {
+			flattenAttributes(block, record, config.FlattenLeafAttrs)
+		}
+		for path, csvField := range countFields {
+			record[csvField] = strconv.Itoa(len(block.FindElements(blockPath(path))))
+		}
+		if config.StripInvisible {
+			for field, value := range record {
+				record[field] = invisibleReplacer.Replace(value)
+			}
+		}
+		if config.UnicodeForm != "" {
+			form := norm.NFC
+			if config.UnicodeForm == "nfd" {
+				form = norm.NFD
+			}
+			for field, value := range record {
+				record[field] = form.String(value)
+			}
+		}
+		for field, options := range config.FieldOptions {
+			if value, ok := record[field]; ok && options["join-newlines"] != "" {
+				separator, _ := optionText(options["join-newlines"])
+				record[field] = joinLines(value, separator)
+			}
+		}
+		for field, value := range record {
+			if trimField(config, field) {
+				record[field] = strings.TrimSpace(value)
+			}
+		}
+		if len(config.NullValues) > 0 {
+			for field, value := range record {
+				if config.NullValues[strings.TrimSpace(value)] {
+					record[field] = ""
+				}
+			}
+		}
+		for field, options := range config.FieldOptions {
+			if value, ok := record[field]; ok {
+				record[field] = trimAffixes(value, options)
+			}
+		}
+		for field, table := range config.Lookups {
+			applyLookup(record, field, table)
+		}
+		for field, options := range config.FieldOptions {
+			value, ok := record[field]
+			switch {
+			case !ok:
+			case options["type"] == "int":
+				if integer, ok := roundInteger(value, options["round"], options); ok {
+					record[field] = integer
+				} else if strings.TrimSpace(value) != "" {
+					notIntegers[field]++
+				}
+			case options["type"] == "number" && options["round"] != "":
+				places, _ := strconv.Atoi(options["round"])
+				if rounded, ok := roundNumber(value, places, options); ok {
+					record[field] = withDecimal(rounded, options)
+				}
+			}
+		}
+		for field, value := range record {
+			if config.Translit || config.FieldOptions[field]["translit"] == "true" {
+				record[field] = transliterate(value)
+			}
+		}
+		for field, options := range config.FieldOptions {
+			if options["split-into"] != "" {
+				splitField(record, field, options, trimField(config, field))
+			}
+		}
+		for field, options := range config.FieldOptions {
+			value, ok := record[field]
+			if !ok || options["maxlen"] == "" {
+				continue
+			}
+			limit, _ := strconv.Atoi(options["maxlen"])
+			length := utf8.RuneCountInString(value)
+			if length <= limit {
+				continue
+			}
+			if options["on-overflow"] == overflowError {
+				return nil, fmt.Errorf(tr("в файле %s значение колонки %q длиннее maxlen=%d: %d символов"), filename, field, limit, length)
+			}
+			record[field] = truncateValue(value, limit, options["ellipsis"])
+			truncated[field]++
+		}
+		if len(record) > 0 {
+			if config.PartitionAttr != "" {
+				record[partitionKey] = partitionValue(block, config.PartitionAttr)
+			}
+			if config.WithSource {
+				record[sourceColumn] = filename
+			}
+			if config.WithXPath {
+				record[xpathColumn] = elementPath(block)
+			}
+			if config.WithBreadcrumb {
+				record[breadcrumbColumn] = ancestorPath(block)
+			}
+			if config.WithRaw {
+				if record[rawColumn], err = blockXML(block); err != nil {
+					return nil, fmt.Errorf(tr("ошибка при сериализации блока в файле %s: %w"), filename, err)
+				}
+			}
+			if config.ConvertedAt != "" {
+				record[convertedAtColumn] = config.ConvertedAt
+			}
+			records = append(records, record)
+			if config.Trace {
+				position := elementPath(block)
+				var rows [][]string
+				for _, column := range config.FieldOrder {
+					if path, ok := traced[column]; ok {
+						rows = append(rows, []string{filename, position, column, path})
+					}
+				}
+				writeTrace(rows)
+			}
+		}
+	}
+	missingFields := make([]string, 0, len(missing))
+	for field := range missing {
+		missingFields = append(missingFields, field)
+	}
+	sort.Strings(missingFields)
+	for _, field := range missingFields {
+		warnf("missing-element", filename, field, "В файле %s пропущено записей без элемента колонки %q: %d\n", filename, field, missing[field])
+	}
+	truncatedFields := make([]string, 0, len(truncated))
+	for field := range truncated {
+		truncatedFields = append(truncatedFields, field)
+	}
+	sort.Strings(truncatedFields)
+	for _, field := range truncatedFields {
+		warnf("truncated", filename, field, "В файле %s обрезано до maxlen=%s значений колонки %q: %d\n", filename, config.FieldOptions[field]["maxlen"], field, truncated[field])
+	}
+	notIntegerFields := make([]string, 0, len(notIntegers))
+	for field := range notIntegers {
+		notIntegerFields = append(notIntegerFields, field)
+	}
+	sort.Strings(notIntegerFields)
+	for _, field := range notIntegerFields {
+		warnf("not-a-number", filename, field, "В файле %s не удалось разобрать как число значений колонки %q с type=int: %d\n", filename, field, notIntegers[field])
+	}
+	for _, nested := range config.Nested {
+		if nestedDropped[nested.Prefix] > 0 {
+			warnf("nested-dropped", filename, nested.Prefix, "В файле %s пропущено элементов %s сверх max=%d: %d\n", filename, nested.FieldMap[parserOpenBlockTagLiteral], nested.Max, nestedDropped[nested.Prefix])
+		}
+	}
+	return records, nil
+}
+
+func findBlocks(doc *etree.Document, blockTag string) ([]*etree.Element, error) {
+	if !strings.Contains(blockTag, "*") {
+		return doc.FindElements("//" + blockTag), nil
+	}
+	if _, err := path.Match(blockTag, ""); err != nil {
+		return nil, fmt.Errorf(tr("недопустимый шаблон тега блока %q: %w"), blockTag, err)
+	}
+
+	var blocks []*etree.Element
+	var walk func(elem *etree.Element)
+	walk = func(elem *etree.Element) {
+		for _, child := range elem.ChildElements() {
+			if matched, _ := path.Match(blockTag, child.Tag); matched {
+				blocks = append(blocks, child)
+			}
+			walk(child)
+		}
+	}
+	walk(&doc.Element)
+	return blocks, nil
+}
+
+func blockXML(block *etree.Element) (string, error) {
+	doc := etree.NewDocument()
+	doc.SetRoot(block.Copy())
+	return doc.WriteToString()
+}
+
+func parseBlockIndices(value string) ([]int, error) {
+	var indices []int
+	for _, part := range strings.Split(value, ",") {
+		index, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || index < 1 {
+			return nil, fmt.Errorf(tr("недопустимый номер блока: %q"), part)
+		}
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+func checkDefaultMapping(filename, configFile string, config *Config) {
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return
+	}
+	blockTag := config.FieldMap[parserOpenBlockTagLiteral]
+	if blocks, err := findBlocks(doc, blockTag); err != nil || len(blocks) > 0 {
+		return
+	}
+	warnf("default-mapping", filename, "", "ВНИМАНИЕ: файл конфигурации %s не найден, используется встроенное сопоставление для таможенных деклараций, но в файле %s нет ни одного блока %s.\nУкажите свою конфигурацию вторым аргументом или через -config-json, либо запустите с -no-defaults -auto-map.\n", configFile, filename, blockTag)
+}
+
+func selectBlocks(blocks []*etree.Element, indices []int, filename string) []*etree.Element {
+	var selected []*etree.Element
+	for i, index := range indices {
+		if i > 0 && index == indices[i-1] {
+			continue
+		}
+		if index > len(blocks) {
+			warnf("missing-block", filename, "", "В файле %s нет блока с номером %d (всего блоков: %d)\n", filename, index, len(blocks))
+			continue
+		}
+		selected = append(selected, blocks[index-1])
+	}
+	return selected
+}
+
+func besideExecutable(name string) string {
+	if _, err := os.Stat(name); err == nil {
+		return name
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return name
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	candidate := filepath.Join(filepath.Dir(exe), name)
+	if _, err := os.Stat(candidate); err != nil {
+		return name
+	}
+	verbosef("Используется %s рядом с программой\n", candidate)
+	return candidate
+}
+
+func readDocument(filename string, config *Config) (*etree.Document, error) {
+	openFiles <- struct{}{}
+	defer func() { <-openFiles }()
+
+	for attempt := 0; ; attempt++ {
+		doc := etree.NewDocument()
+		doc.ReadSettings.Permissive = config.Permissive
+		doc.ReadSettings.PreserveCData = config.PreserveCData
+		var err error
+		switch {
+		case config.MultiDoc:
+			err = readMultiDocument(doc, filename, config)
+		case isURL(filename):
+			var data []byte
+			if data, err = fetchURL(filename, config.HTTP); err == nil {
+				err = doc.ReadFromBytes(data)
+			}
+		default:
+			err = doc.ReadFromFile(filename)
+		}
+		if err == nil {
+			return doc, nil
+		}
+		var pathErr *fs.PathError
+		var urlErr *url.Error
+		if attempt >= config.ReadRetries || !errors.As(err, &pathErr) && !errors.As(err, &urlErr) {
+			return nil, err
+		}
+		delay := readRetryBackoff << attempt
+		verbosef("Повторное чтение файла %s через %v (попытка %d из %d): %v\n", filename, delay, attempt+1, config.ReadRetries, err)
+		time.Sleep(delay)
+	}
+}
+
+func verbosef(format string, args ...any) {
+	if verbose {
+		fmt.Printf(tr(format), args...)
+	}
+}
+
+type fieldMapping struct {
+	csvField string
+	sources  []fieldSource
+}
+
+type fieldSource struct {
+	path string
+	attr string
+}
+
+func trimField(config *Config, field string) bool {
+	switch config.FieldOptions[field]["trim"] {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return config.Trim
+}
+
+func compileMappings(config *Config) ([]fieldMapping, map[string]string) {
+	xmlTags := make([]string, 0, len(config.FieldMap))
+	for xmlTag := range config.FieldMap {
+		if xmlTag != parserOpenBlockTagLiteral {
+			xmlTags = append(xmlTags, xmlTag)
+		}
+	}
+	sort.Strings(xmlTags)
+
+	var mappings []fieldMapping
+	countFields := make(map[string]string)
+	for _, xmlTag := range xmlTags {
+		csvField := config.FieldMap[xmlTag]
+		if strings.HasPrefix(xmlTag, countPrefix) {
+			countFields[strings.TrimPrefix(xmlTag, countPrefix)] = csvField
+			continue
+		}
+		mapping := fieldMapping{csvField: csvField}
+		for _, alternative := range strings.Split(xmlTag, "|") {
+			path, attr := splitAttr(strings.TrimSpace(alternative))
+			mapping.sources = append(mapping.sources, fieldSource{path: path, attr: attr})
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, countFields
+}
+
+func (m fieldMapping) resolve(block *etree.Element, elements map[string][]*etree.Element, config *Config) (string, bool) {
+	value, _, found := m.match(block, elements, config)
+	return value, found
+}
+
+func (m fieldMapping) match(block *etree.Element, elements map[string][]*etree.Element, config *Config) (string, sourceMatch, bool) {
+	found := false
+	var first sourceMatch
+	tagName := config.FieldOptions[m.csvField]["emit"] == emitTagName
+	unwrap := config.FieldOptions[m.csvField]["unwrap"] == "true"
+	trim := trimField(config, m.csvField)
+	blank := trim || config.BlankAsEmpty
+	maxDepth, _ := strconv.Atoi(config.FieldOptions[m.csvField]["max-depth"])
+	for _, source := range m.sources {
+		elems := elements[source.path]
+		if maxDepth > 0 {
+			elems = withinDepth(elems, block, maxDepth)
+		}
+		value, matched, ok := source.match(elems, config, unwrap, trim)
+		if !ok {
+			continue
+		}
+		if value != "" && !(blank && strings.TrimSpace(value) == "") {
+			if tagName {
+				value = matched[0].Tag
+			}
+			return value, sourceMatch{source, matched}, true
+		}
+		if !found {
+			first = sourceMatch{source, matched}
+		}
+		found = true
+	}
+	if tagName && found && len(first.elems) > 0 {
+		return first.elems[0].Tag, first, true
+	}
+	return "", first, found
+}
+
+func withinDepth(elems []*etree.Element, block *etree.Element, maxDepth int) []*etree.Element {
+	var kept []*etree.Element
+	for _, elem := range elems {
+		depth := 0
+		e := elem
+		for ; e != nil && e != block; e = e.Parent() {
+			depth++
+		}
+		if e == nil || depth <= maxDepth {
+			kept = append(kept, elem)
+		}
+	}
+	return kept
+}
+
+func preferLanguage(elems []*etree.Element, attr, lang string) []*etree.Element {
+	var matching []*etree.Element
+	for _, elem := range elems {
+		value := elem.SelectAttrValue(attr, "")
+		if strings.EqualFold(value, lang) || len(value) > len(lang) && strings.EqualFold(value[:len(lang)+1], lang+"-") {
+			matching = append(matching, elem)
+		}
+	}
+	if len(matching) == 0 {
+		return elems
+	}
+	return matching
+}
+
+func (s fieldSource) value(elems []*etree.Element, config *Config, unwrap, trim bool) (string, bool) {
+	value, _, ok := s.match(elems, config, unwrap, trim)
+	return value, ok
+}
+
+func (s fieldSource) match(elems []*etree.Element, config *Config, unwrap, trim bool) (string, []*etree.Element, bool) {
+	if config.LangAttr != "" && len(elems) > 1 {
+		elems = preferLanguage(elems, config.LangAttrName, config.LangAttr)
+	}
+	var values []string
+	var matched []*etree.Element
+	for _, elem := range elems {
+		if value, ok := s.elementValue(elem, unwrap); ok {
+			values = append(values, value)
+			matched = append(matched, elem)
+		}
+	}
+	if len(values) == 0 {
+		return "", nil, false
+	}
+
+	switch config.OnDuplicate {
+	case duplicateLast:
+		return values[len(values)-1], matched[len(matched)-1:], true
+	case duplicateJoin:
+		var parts []string
+		var used []*etree.Element
+		for i, value := range values {
+			if trim {
+				value = strings.TrimSpace(value)
+			}
+			if value != "" {
+				parts = append(parts, value)
+				used = append(used, matched[i])
+			}
+		}
+		return strings.Join(parts, config.DuplicateSeparator), used, true
+	default:
+		return values[0], matched[:1], true
+	}
+}
+
+type sourceMatch struct {
+	source fieldSource
+	elems  []*etree.Element
+}
+
+func (m sourceMatch) paths() []string {
+	paths := make([]string, len(m.elems))
+	for i, elem := range m.elems {
+		paths[i] = elementPath(elem)
+		if m.source.attr != "" {
+			paths[i] += "/@" + m.source.attr
+		}
+	}
+	return paths
+}
+
+func (s fieldSource) elementValue(elem *etree.Element, unwrap bool) (string, bool) {
+	if s.attr == "" {
+		if unwrap {
+			return unwrapText(elem), true
+		}
+		return elem.Text(), true
+	}
+	if attr := elem.SelectAttr(s.attr); attr != nil {
+		return attr.Value, true
+	}
+	return "", false
+}
+
+func unwrapText(elem *etree.Element) string {
+	for {
+		children := elem.ChildElements()
+		if len(children) != 1 {
+			return elem.Text()
+		}
+		for _, token := range elem.Child {
+			if data, ok := token.(*etree.CharData); ok && strings.TrimSpace(data.Data) != "" {
+				return elem.Text()
+			}
+		}
+		elem = children[0]
+	}
+}
+
+func splitAttr(xmlTag string) (string, string) {
+	i := strings.LastIndex(xmlTag, "@")
+	if i <= 0 || strings.ContainsAny(xmlTag[i+1:], "/[]()='\"") {
+		return xmlTag, ""
+	}
+	return xmlTag[:i], xmlTag[i+1:]
+}
+
+func blockPath(path string) string {
+	if strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") || strings.HasPrefix(path, "/") {
+		return path
+	}
+	return ".//" + path
+}
+
+func isPlainTag(xmlTag string) bool {
+	return !strings.ContainsAny(xmlTag, ":/[]@.*()|")
+}
+
+func mappingSources(mappings []fieldMapping) (map[string]bool, []string) {
+	tags := make(map[string]bool)
+	var paths []string
+	for _, mapping := range mappings {
+		for _, source := range mapping.sources {
+			if isPlainTag(source.path) {
+				tags[source.path] = true
+			} else {
+				paths = append(paths, source.path)
+			}
+		}
+	}
+	return tags, paths
+}
+
+func gatherElements(elem *etree.Element, tags map[string]bool, paths []string) map[string][]*etree.Element {
+	elements := make(map[string][]*etree.Element)
+	collectElements(elem, tags, elements)
+	for _, path := range paths {
+		if _, found := elements[path]; !found {
+			elements[path] = elem.FindElements(blockPath(path))
+		}
+	}
+	return elements
+}
+
+func collectElements(block *etree.Element, tags map[string]bool, elements map[string][]*etree.Element) {
+	queue := []*etree.Element{block}
+	for len(queue) > 0 {
+		elem := queue[0]
+		queue = queue[1:]
+		for _, child := range elem.ChildElements() {
+			if tags[child.Tag] {
+				elements[child.Tag] = append(elements[child.Tag], child)
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+func elementPath(elem *etree.Element) string {
+	var segments []string
+	for e := elem; e != nil && e.Tag != ""; e = e.Parent() {
+		segments = append(segments, fmt.Sprintf("%s[%d]", e.FullTag(), siblingPosition(e)))
+	}
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return "/" + strings.Join(segments, "/")
+}
+
+func ancestorPath(elem *etree.Element) string {
+	var segments []string
+	for e := elem.Parent(); e != nil && e.Tag != ""; e = e.Parent() {
+		segments = append(segments, e.FullTag())
+	}
+	slices.Reverse(segments)
+	return "/" + strings.Join(segments, "/")
+}
+
+func siblingPosition(elem *etree.Element) int {
+	parent := elem.Parent()
+	if parent == nil {
+		return 1
+	}
+	position := 0
+	for _, sibling := range parent.ChildElements() {
+		if sibling.FullTag() == elem.FullTag() {
+			position++
+		}
+		if sibling == elem {
+			break
+		}
+	}
+	return position
+}
+
+func parseBlockFilter(definition string) (BlockFilter, error) {
+	attr, value, ok := strings.Cut(definition, "=")
+	if attr = strings.TrimSpace(attr); !ok || attr == "" {
+		return BlockFilter{}, fmt.Errorf("%s %s", tr("Недопустимый фильтр -block-filter, ожидается АТРИБУТ=ЗНАЧЕНИЕ:"), definition)
+	}
+	return BlockFilter{Attr: attr, Value: value}, nil
+}
+
+func matchesFilters(block *etree.Element, filters []BlockFilter) bool {
+	for _, filter := range filters {
+		attr := block.SelectAttr(filter.Attr)
+		if attr == nil || attr.Value != filter.Value {
+			return false
+		}
+	}
+	return true
+}
+
+func autoMapLeaves(block *etree.Element, config *Config) ([]sampleTag, error) {
+	var leaves []sampleTag
+	seen := make(map[string]bool)
+	owners := make(map[string]string)
+	suffixes := make(map[string]int)
+	var walk func(elem *etree.Element) error
+	walk = func(elem *etree.Element) error {
+		for _, child := range elem.ChildElements() {
+			if len(child.ChildElements()) > 0 {
+				if err := walk(child); err != nil {
+					return err
+				}
+				continue
+			}
+			full := child.FullTag()
+			if seen[full] {
+				continue
+			}
+			seen[full] = true
+			column := child.Tag
+			if config.AutoMapPrefix == prefixKeep {
+				column = full
+			}
+			if owner, taken := owners[column]; taken {
+				switch config.AutoMapCollision {
+				case collisionError:
+					return fmt.Errorf(tr("теги %s и %s дают одну колонку %q"), owner, full, column)
+				case collisionSuffix:
+					suffixes[column]++
+					column = column + "_" + strconv.Itoa(suffixes[column]+1)
+				default:
+					continue
+				}
+			}
+			owners[column] = full
+			leaves = append(leaves, sampleTag{tag: column, elem: child})
+		}
+		return nil
+	}
+	if err := walk(block); err != nil {
+		return nil, err
+	}
+	return leaves, nil
+}
+
+func flattenAttributes(block *etree.Element, record Record, leaves bool) {
+	for _, attr := range block.Attr {
+		column := "@" + attr.FullKey()
+		if _, found := record[column]; !found {
+			record[column] = attr.Value
+		}
+	}
+	if !leaves {
+		return
+	}
+	for _, leaf := range sampleLeafTags(block) {
+		for _, attr := range leaf.elem.Attr {
+			column := leaf.tag + "@" + attr.FullKey()
+			if _, found := record[column]; !found {
+				record[column] = attr.Value
+			}
+		}
+	}
+}
+
+func shouldSkip(block *etree.Element, rules []SkipRule) bool {
+	for _, rule := range rules {
+		elem := block.FindElement(".//" + rule.Tag)
+		if elem != nil && elem.Text() == rule.Value {
+			return true
+		}
+	}
+	return false
+}
+
+func outputFilename(output, timestampFormat, ext string, now time.Time) (string, error) {
+	if output != "" {
+		return output, nil
+	}
+	timestamp := now.Format(timestampFormat)
+	if timestamp == "" || strings.ContainsAny(timestamp, "<>:\"/\\|?*") || strings.ContainsFunc(timestamp, unicode.IsControl) {
+		return "", fmt.Errorf(tr("формат времени %q даёт недопустимое имя файла: %q"), timestampFormat, timestamp)
+	}
+	return "result_" + timestamp + ext, nil
+}
+
+func perFileName(input, outDir, ext string, compressed bool) string {
+	base := filepath.Base(input)
+	name := strings.TrimSuffix(base, filepath.Ext(base)) + ext
+	if compressed {
+		name += ".gz"
+	}
+	return filepath.Join(outDir, name)
+}
+
+func siblingReportName(filename, suffix string) string {
+	base, _ := strings.CutSuffix(filename, ".gz")
+	return strings.TrimSuffix(base, filepath.Ext(base)) + suffix
+}
+
+func insertSuffix(filename, suffix string) string {
+	base, gz := strings.CutSuffix(filename, ".gz")
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext) + suffix + ext
+	if gz {
+		name += ".gz"
+	}
+	return name
+}
+
+func chunkRecords(set outputSet, size int) []outputSet {
+	if size == 0 {
+		return []outputSet{set}
+	}
+	var chunks []outputSet
+	for start := 0; start == 0 || start < len(set.records); start += size {
+		end := min(start+size, len(set.records))
+		chunks = append(chunks, outputSet{
+			filename: insertSuffix(set.filename, fmt.Sprintf("_part%03d", len(chunks)+1)),
+			records:  set.records[start:end],
+		})
+	}
+	return chunks
+}
+
+func resolveExisting(filename, mode string) (string, bool, error) {
+	if _, err := os.Stat(filename); errors.Is(err, fs.ErrNotExist) {
+		return filename, true, nil
+	} else if err != nil {
+		return "", false, fmt.Errorf(tr("ошибка при проверке файла результата: %w"), err)
+	}
+
+	switch mode {
+	case ifExistsSkip:
+		fmt.Printf(tr("Файл %s уже существует, запись пропущена\n"), filename)
+		return "", false, nil
+	case ifExistsError:
+		return "", false, fmt.Errorf(tr("файл %s уже существует"), filename)
+	case ifExistsRename:
+		for n := 1; ; n++ {
+			candidate := insertSuffix(filename, fmt.Sprintf("_%d", n))
+			if _, err := os.Stat(candidate); errors.Is(err, fs.ErrNotExist) {
+				fmt.Printf(tr("Файл %s уже существует, результат записан в %s\n"), filename, candidate)
+				return candidate, true, nil
+			}
+		}
+	default:
+		return filename, true, nil
+	}
+}
+
+func createOutput(filename string, config *Config, write func(out io.Writer) error) (err error) {
+	if config.Zip != nil {
+		entry, err := config.Zip.CreateHeader(&zip.FileHeader{
+			Name:     filepath.Base(filename),
+			Method:   zip.Deflate,
+			Modified: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf(tr("ошибка при создании записи архива: %w"), err)
+		}
+		return pipeOutput(entry, config, write)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при создании файла результата: %w"), err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf(tr("ошибка при закрытии файла результата: %w"), closeErr)
+		}
+	}()
+
+	var out io.Writer = file
+	if config.Checksum != "" {
+		hasher := newHasher(config.Checksum)
+		out = io.MultiWriter(file, hasher)
+		defer func() {
+			if err == nil {
+				err = writeChecksum(filename, config.Checksum, hasher)
+			}
+		}()
+	}
+	if config.BufferSize > 0 {
+		buffered := bufio.NewWriterSize(out, config.BufferSize)
+		defer func() {
+			if flushErr := buffered.Flush(); flushErr != nil && err == nil {
+				err = fmt.Errorf(tr("ошибка при записи файла результата: %w"), flushErr)
+			}
+		}()
+		out = buffered
+	}
+	if config.Gzip {
+		gz := gzip.NewWriter(out)
+		defer func() {
+			if closeErr := gz.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf(tr("ошибка при сжатии файла результата: %w"), closeErr)
+			}
+		}()
+		out = gz
+	}
+	return pipeOutput(out, config, write)
+}
+
+func pipeOutput(out io.Writer, config *Config, write func(out io.Writer) error) error {
+	if config.PostCommand == "" {
+		return writeEncoded(out, config, write)
+	}
+	return runPostCommand(config.PostCommand, out, func(in io.Writer) error {
+		return writeEncoded(in, config, write)
+	})
+}
+
+func writeEncoded(out io.Writer, config *Config, write func(out io.Writer) error) error {
+	switch outputEncoding(config) {
+	case encodingCP1251:
+		out = charmap.Windows1251.NewEncoder().Writer(out)
+	case encodingUTF16LE:
+		out = unicodeenc.UTF16(unicodeenc.LittleEndian, unicodeenc.UseBOM).NewEncoder().Writer(out)
+	case encodingUTF8BOM:
+		if _, err := io.WriteString(out, "\uFEFF"); err != nil {
+			return fmt.Errorf(tr("ошибка при записи BOM: %w"), err)
+		}
+	}
+	return write(out)
+}
+
+func writeOutput(filename string, records []Record, config *Config) error {
+	switch config.Format {
+	case formatXML:
+		return writeXML(filename, records, config)
+	case formatJSON, formatNDJSON:
+		return writeJSON(filename, records, config)
+	case formatParquet:
+		return writeParquet(filename, records, config)
+	}
+	return writeCSV(filename, records, config)
+}
+
+func reportConfig(config *Config, fieldOrder []string) *Config {
+	report := *config
+	report.FieldOrder = fieldOrder
+	report.Columns = nil
+	report.Preamble = nil
+	report.Gzip = false
+	report.Checksum = ""
+	report.PostCommand = ""
+	return &report
+}
+
+func writeCSV(filename string, records []Record, config *Config) error {
+	return createOutput(filename, config, func(out io.Writer) error {
+		return writeCSVRows(out, records, config)
+	})
+}
+
+func newCSVRowWriter(out io.Writer, config *Config) (rowWriter, error) {
+	lineEnd := "\n"
+	if config.CRLF {
+		lineEnd = "\r\n"
+	}
+	for _, line := range config.Preamble {
+		if _, err := io.WriteString(out, line+lineEnd); err != nil {
+			return nil, fmt.Errorf(tr("ошибка при записи преамбулы: %w"), err)
+		}
+	}
+
+	return newRowEncoder(out, config), nil
+}
+
+func newRowEncoder(out io.Writer, config *Config) rowWriter {
+	if config.QuoteAll {
+		return newQuoteAllWriter(out, config.Delimiter, config.CRLF)
+	}
+	writer := csv.NewWriter(out)
+	writer.Comma = config.Delimiter
+	writer.UseCRLF = config.CRLF
+	return writer
+}
+
+func writeCSVRows(out io.Writer, records []Record, config *Config) error {
+	writer, err := newCSVRowWriter(out, config)
+	if err != nil {
+		return err
+	}
+
+	if len(records) == 0 && !config.HeaderOnEmpty {
+		return nil
+	}
+
+	headers := getHeaders(records, config)
+	if err := writer.Write(headers); err != nil {
+		return fmt.Errorf(tr("ошибка при записи заголовков: %w"), err)
+	}
+
+	if config.RowWorkers > 1 {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf(tr("ошибка при записи CSV файла: %w"), err)
+		}
+		return writeRowsParallel(out, records, headers, config)
+	}
+
+	for _, record := range records {
+		row := make([]string, len(headers))
+		for i, header := range headers {
+			row[i] = csvCell(config, header, record[header])
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf(tr("ошибка при записи строки: %w"), err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf(tr("ошибка при записи CSV файла: %w"), err)
+	}
+	return nil
+}
+
+func requireColumns(records []Record, columns []string, dropped map[string]int, rejected []Rejection) ([]Record, []Rejection) {
+	kept := records[:0]
+	for _, record := range records {
+		missing := ""
+		for _, column := range columns {
+			if record[column] == "" {
+				missing = column
+				break
+			}
+		}
+		if missing != "" {
+			dropped[missing]++
+			rejected = append(rejected, Rejection{Record: record, Reason: "require:" + missing})
+			continue
+		}
+		kept = append(kept, record)
+	}
+	return kept, rejected
+}
+
+func writeRejects(filename string, rejected []Rejection, config *Config) error {
+	rejectConfig := reportConfig(config, append(append([]string(nil), config.FieldOrder...), rejectReasonColumn))
+	if len(config.Columns) > 0 {
+		rejectConfig.Columns = append(append([]string(nil), config.Columns...), rejectReasonColumn)
+	}
+
+	records := make([]Record, 0, len(rejected))
+	for _, rejection := range rejected {
+		record := make(Record, len(rejection.Record)+1)
+		for field, value := range rejection.Record {
+			record[field] = value
+		}
+		record[rejectReasonColumn] = rejection.Reason
+		records = append(records, record)
+	}
+	return writeCSV(filename, records, rejectConfig)
+}
+
+func checkStrictRows(records []Record, config *Config) error {
+	known := make(map[string]bool, len(config.FieldOrder))
+	for _, field := range config.FieldOrder {
+		known[field] = true
+	}
+	unexpected := make(map[string]int)
+	for _, record := range records {
+		for field := range record {
+			if !known[field] {
+				unexpected[field]++
+			}
+		}
+	}
+	if len(unexpected) == 0 {
+		return nil
+	}
+
+	fields := make([]string, 0, len(unexpected))
+	for field := range unexpected {
+		fields = append(fields, fmt.Sprintf(tr("%q (записей: %d)"), field, unexpected[field]))
+	}
+	sort.Strings(fields)
+	return fmt.Errorf(tr("в записях есть колонки вне заданного порядка полей: %s"), strings.Join(fields, ", "))
+}
+
+func reportEmptyColumns(records []Record, config *Config, threshold float64) {
+	for _, header := range getHeaders(records, config) {
+		empty := 0
+		for _, record := range records {
+			if record[header] == "" {
+				empty++
+			}
+		}
+		ratio := float64(empty) / float64(len(records))
+		if ratio >= threshold {
+			warnf("empty-column", "", header, "Колонка %q пуста в %.0f%% записей, возможно сопоставление устарело\n", header, ratio*100)
+		}
+	}
+}
+
+func outputEncoding(config *Config) string {
+	if config.Encoding != "" {
+		return config.Encoding
+	}
+	if isWindows {
+		return encodingCP1251
+	}
+	return encodingUTF8
+}
+
+func newHasher(algorithm string) hash.Hash {
+	if algorithm == checksumMD5 {
+		return md5.New()
+	}
+	return sha256.New()
+}
+
+func writeChecksum(filename, algorithm string, hasher hash.Hash) error {
+	line := fmt.Sprintf("%x  %s\n", hasher.Sum(nil), filepath.Base(filename))
+	if err := os.WriteFile(filename+"."+algorithm, []byte(line), 0o644); err != nil {
+		return fmt.Errorf(tr("ошибка при записи контрольной суммы: %w"), err)
+	}
+	return nil
+}
+
+func transposeRecords(records []Record, config *Config) ([]Record, *Config) {
+	transposed := *config
+	transposed.Columns = []string{transposeFieldColumn}
+	for i := range records {
+		transposed.Columns = append(transposed.Columns, strconv.Itoa(i+1))
+	}
+
+	var rows []Record
+	for _, header := range getHeaders(records, config) {
+		row := Record{transposeFieldColumn: header}
+		for i, record := range records {
+			value := record[header]
+			if config.Format == formatCSV {
+				value = csvCell(config, header, value)
+			}
+			row[strconv.Itoa(i+1)] = value
+		}
+		rows = append(rows, row)
+	}
+	return rows, &transposed
+}
+
+func readColumnsFile(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var columns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		columns = append(columns, line)
+	}
+	return columns, scanner.Err()
+}
+
+func reportUnknownColumns(records []Record, columns []string) {
+	for _, column := range columns {
+		found := false
+		for _, record := range records {
+			if _, found = record[column]; found {
+				break
+			}
+		}
+		if !found {
+			warnf("unknown-column", "", column, "Колонка %q не найдена ни в одной записи\n", column)
+		}
+	}
+}
+
+func appendAutoColumns(config *Config, records []Record) {
+	known := make(map[string]bool)
+	for _, field := range config.FieldOrder {
+		known[field] = true
+	}
+	var added []string
+	for _, record := range records {
+		for key := range record {
+			if !known[key] {
+				known[key] = true
+				added = append(added, key)
+			}
+		}
+	}
+	sort.Strings(added)
+	config.FieldOrder = append(config.FieldOrder, added...)
+}
+
+func getHeaders(records []Record, config *Config) []string {
+	if len(config.Columns) > 0 {
+		return config.Columns
+	}
+
+	var headers []string
+	usedFields := make(map[string]bool)
+
+	for _, csvField := range config.FieldOrder {
+		if !usedFields[csvField] {
+			headers = append(headers, csvField)
+			usedFields[csvField] = true
+		}
+	}
+
+	for _, record := range records {
+		for key := range record {
+			if !usedFields[key] {
+				headers = append(headers, key)
+				usedFields[key] = true
+			}
+		}
+	}
+
+	return headers
+}
+
+func sortHeaders(headers []string, collation string, rowNumber bool) {
+	if rowNumber && len(headers) > 0 && headers[0] == rowNumberColumn {
+		headers = headers[1:]
+	}
+	if collation == collationRU {
+		collate.New(language.Russian, collate.IgnoreCase).SortStrings(headers)
+		return
+	}
+	sort.Strings(headers)
 }
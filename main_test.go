@@ -0,0 +1,566 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/md5"
+	"crypto/sha256"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/beevik/etree"
+)
+
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	filename := filepath.Join(dir, name)
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func loadTestConfig(tb testing.TB, configFile string, noDefaults bool) *Config {
+	tb.Helper()
+	config, err := loadConfig(configFile, nil, noDefaults)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return config
+}
+
+func runArgs(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	out, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = out.Close() }()
+	stdout, osArgs, commandLine := os.Stdout, os.Args, flag.CommandLine
+	defer func() { os.Stdout, os.Args, flag.CommandLine = stdout, osArgs, commandLine }()
+
+	flag.CommandLine = flag.NewFlagSet("xml_to_csv", flag.ContinueOnError)
+	os.Args = append([]string{"xml_to_csv"}, args...)
+	os.Stdout = out
+	code := run()
+	os.Stdout = stdout
+
+	data, err := os.ReadFile(out.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(data)
+}
+
+func goodsDocument(numbers ...int) string {
+	var doc strings.Builder
+	doc.WriteString("<ESADout_CU>")
+	for _, number := range numbers {
+		fmt.Fprintf(&doc, "<ESADout_CUGoods><GoodsNumeric>%d</GoodsNumeric><GoodsDescription>Товар %d</GoodsDescription></ESADout_CUGoods>", number, number)
+	}
+	doc.WriteString("</ESADout_CU>")
+	return doc.String()
+}
+
+func readLines(t *testing.T, filename string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestShouldSkip(t *testing.T) {
+	doc := etree.NewDocument()
+	if err := doc.ReadFromString(`<Item><Status>deleted</Status><Sub><Flag>1</Flag></Sub><Empty/></Item>`); err != nil {
+		t.Fatal(err)
+	}
+	block := doc.Root()
+	tests := []struct {
+		name  string
+		rules []SkipRule
+		want  bool
+	}{
+		{"без правил", nil, false},
+		{"совпадение", []SkipRule{{Tag: "Status", Value: "deleted"}}, true},
+		{"другое значение", []SkipRule{{Tag: "Status", Value: "active"}}, false},
+		{"вложенный тег", []SkipRule{{Tag: "Flag", Value: "1"}}, true},
+		{"пустой тег", []SkipRule{{Tag: "Empty", Value: ""}}, true},
+		{"нет тега", []SkipRule{{Tag: "Missing", Value: ""}}, false},
+		{"второе правило", []SkipRule{{Tag: "Status", Value: "active"}, {Tag: "Flag", Value: "1"}}, true},
+		{"регистр значения", []SkipRule{{Tag: "Status", Value: "Deleted"}}, false},
+	}
+	for _, tt := range tests {
+		if got := shouldSkip(block, tt.rules); got != tt.want {
+			t.Errorf("%s: shouldSkip = %v; ожидалось %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseXMLSkipsPlaceholderBlocks(t *testing.T) {
+	dir := t.TempDir()
+	configFile := writeTestFile(t, dir, "cfg", "skip-if:GoodsNumeric=0\n")
+	filename := writeTestFile(t, dir, "declaration.xml", `<ESADout_CU>
+	<ESADout_CUGoods><GoodsNumeric>0</GoodsNumeric><GoodsDescription>ЗАГЛУШКА</GoodsDescription></ESADout_CUGoods>
+	<ESADout_CUGoods><GoodsNumeric>1</GoodsNumeric><GoodsDescription>Болт</GoodsDescription></ESADout_CUGoods>
+	<ESADout_CUGoods><GoodsNumeric>2</GoodsNumeric><GoodsDescription>Гайка</GoodsDescription></ESADout_CUGoods>
+</ESADout_CU>`)
+
+	records, err := parseXML(filename, loadTestConfig(t, configFile, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("записей %d, ожидалось 2: %v", len(records), records)
+	}
+	for i, want := range []string{"Болт", "Гайка"} {
+		if got := records[i]["Название"]; got != want {
+			t.Errorf("запись %d: Название = %q; ожидалось %q", i, got, want)
+		}
+		if records[i]["Номер"] == "0" {
+			t.Errorf("запись %d: блок-заглушка попал в результат", i)
+		}
+	}
+}
+
+func TestParseXMLXPath(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeTestFile(t, dir, "declaration.xml", `<ESADout_CU>
+	<Goods><ESADout_CUGoods><GoodsNumeric>1</GoodsNumeric></ESADout_CUGoods></Goods>
+	<Goods>
+		<ESADout_CUGoods><GoodsNumeric>2</GoodsNumeric></ESADout_CUGoods>
+		<ESADout_CUGoods><GoodsNumeric>3</GoodsNumeric></ESADout_CUGoods>
+	</Goods>
+</ESADout_CU>`)
+	config := loadTestConfig(t, filepath.Join(dir, "missing"), false)
+	config.WithXPath = true
+
+	records, err := parseXML(filename, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"/ESADout_CU[1]/Goods[1]/ESADout_CUGoods[1]",
+		"/ESADout_CU[1]/Goods[2]/ESADout_CUGoods[1]",
+		"/ESADout_CU[1]/Goods[2]/ESADout_CUGoods[2]",
+	}
+	if len(records) != len(want) {
+		t.Fatalf("записей %d, ожидалось %d", len(records), len(want))
+	}
+	for i, path := range want {
+		if got := records[i][xpathColumn]; got != path {
+			t.Errorf("запись %d: %s = %q; ожидалось %q", i, xpathColumn, got, path)
+		}
+	}
+}
+
+func TestLoadConfigNoDefaults(t *testing.T) {
+	dir := t.TempDir()
+	configFile := writeTestFile(t, dir, "cfg", "parser_open_block_tag=Item\nSku=Артикул\n")
+
+	config := loadTestConfig(t, configFile, true)
+	if len(config.FieldOrder) != 1 || config.FieldOrder[0] != "Артикул" {
+		t.Errorf("FieldOrder = %v; ожидалось [Артикул]", config.FieldOrder)
+	}
+	if _, exists := config.FieldMap["GoodsNumeric"]; exists {
+		t.Error("встроенное сопоставление GoodsNumeric осталось при -no-defaults")
+	}
+	if config.FieldMap[parserOpenBlockTagLiteral] != "Item" {
+		t.Errorf("%s = %q; ожидалось Item", parserOpenBlockTagLiteral, config.FieldMap[parserOpenBlockTagLiteral])
+	}
+
+	if config := loadTestConfig(t, configFile, false); len(config.FieldOrder) != 15 {
+		t.Errorf("без -no-defaults колонок %d; ожидалось 15", len(config.FieldOrder))
+	}
+}
+
+func TestLoadConfigDirectory(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := loadConfig(dir, nil, false); err == nil || !strings.Contains(err.Error(), "каталог") {
+		t.Errorf("loadConfig(каталог) = %v; ожидалась ошибка о каталоге", err)
+	}
+	configFile := writeTestFile(t, dir, "cfg", "Sku="+strings.Repeat("x", 70000)+"\n")
+	if _, err := loadConfig(configFile, nil, false); err == nil {
+		t.Error("loadConfig не вернул ошибку чтения конфигурации")
+	}
+}
+
+func TestWriteCSVEncodingOverridesWindows(t *testing.T) {
+	saved := isWindows
+	isWindows = true
+	defer func() { isWindows = saved }()
+	dir := t.TempDir()
+
+	records := []Record{{"Название": "Болт"}}
+	tests := []struct {
+		encoding string
+		utf8     bool
+	}{
+		{encodingUTF8, true},
+		{"", false},
+	}
+	for i, tt := range tests {
+		config := &Config{FieldOrder: []string{"Название"}, Encoding: tt.encoding, Delimiter: ';'}
+		filename := filepath.Join(dir, fmt.Sprintf("result_%d.csv", i))
+		if err := writeCSV(filename, records, config); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := utf8.Valid(data) && string(data) == "Название\nБолт\n"; got != tt.utf8 {
+			t.Errorf("-encoding %q: результат в UTF-8 = %v; ожидалось %v (%q)", tt.encoding, got, tt.utf8, data)
+		}
+	}
+}
+
+func TestParseXMLPrefersShallowestMatch(t *testing.T) {
+	dir := t.TempDir()
+	filename := writeTestFile(t, dir, "items.xml", `<Root><Item><A><X>deep</X></A><X>shallow</X></Item></Root>`)
+	config := &Config{
+		FieldOrder: []string{"Поле"},
+		FieldMap:   map[string]string{parserOpenBlockTagLiteral: "Item", "X": "Поле"},
+	}
+
+	records, err := parseXML(filename, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("записей %d, ожидалось 1", len(records))
+	}
+	if got := records[0]["Поле"]; got != "shallow" {
+		t.Errorf("Поле = %q; ожидалось %q", got, "shallow")
+	}
+}
+
+func TestCollectElementsMatchesFindElement(t *testing.T) {
+	documents := []string{
+		`<Item><A><X>deep</X></A><X>shallow</X></Item>`,
+		`<Item><A><B><X>3</X></B></A><C><X>2</X><Y>y</Y></C></Item>`,
+		`<Item><A><X>1</X><X>2</X></A><B><X>3</X></B></Item>`,
+		`<Item><Item><X>inner</X></Item><Y><X>outer</X></Y></Item>`,
+		`<Item><A><Y>1</Y></A><Y>2</Y><B><Item>nested</Item></B></Item>`,
+		`<Item>text<X/><A><X>late</X></A></Item>`,
+	}
+	tags := []string{"X", "Y", "Item", "Missing"}
+	tagSet := make(map[string]bool)
+	for _, tag := range tags {
+		tagSet[tag] = true
+	}
+
+	for _, document := range documents {
+		doc := etree.NewDocument()
+		if err := doc.ReadFromString(document); err != nil {
+			t.Fatal(err)
+		}
+		block := doc.Root()
+		elements := make(map[string][]*etree.Element)
+		collectElements(block, tagSet, elements)
+		for _, tag := range tags {
+			want, got := block.FindElements(".//"+tag), elements[tag]
+			if len(got) != len(want) {
+				t.Errorf("%s: %s найдено %d; FindElements дал %d", document, tag, len(got), len(want))
+				continue
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("%s: %s[%d] = %v; FindElements дал %v", document, tag, i, got[i], want[i])
+				}
+			}
+		}
+	}
+}
+
+func TestRunProcessesAllFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	for i := 0; i < 40; i++ {
+		writeTestFile(t, dataDir, fmt.Sprintf("d%02d.xml", i), goodsDocument(2*i+1, 2*i+2))
+	}
+	output := filepath.Join(t.TempDir(), "result.csv")
+
+	code, out := runArgs(t, "-workers", "3", "-output", output, dataDir, filepath.Join(dataDir, "missing.cfg"))
+	if code != exitOK {
+		t.Fatalf("код %d; ожидался %d\n%s", code, exitOK, out)
+	}
+	if lines := readLines(t, output); len(lines) != 81 {
+		t.Errorf("строк %d; ожидалось 81 (заголовок и 80 записей)", len(lines))
+	}
+}
+
+func TestRunReportsFileErrors(t *testing.T) {
+	dataDir := t.TempDir()
+	for i := 0; i < 10; i++ {
+		writeTestFile(t, dataDir, fmt.Sprintf("d%02d.xml", i), goodsDocument(i+1))
+	}
+	broken := writeTestFile(t, dataDir, "broken.xml", "<ESADout_CU><ESADout_CUGoods>")
+	config := filepath.Join(dataDir, "missing.cfg")
+
+	output := filepath.Join(t.TempDir(), "result.csv")
+	code, out := runArgs(t, "-workers", "4", "-output", output, dataDir, config)
+	if code != exitOK {
+		t.Fatalf("без -fail-fast код %d; ожидался %d\n%s", code, exitOK, out)
+	}
+	if !strings.Contains(out, broken) {
+		t.Errorf("ошибка чтения %s не выведена:\n%s", broken, out)
+	}
+	if lines := readLines(t, output); len(lines) != 11 {
+		t.Errorf("строк %d; ожидалось 11", len(lines))
+	}
+
+	output = filepath.Join(t.TempDir(), "result.csv")
+	code, out = runArgs(t, "-fail-fast", "-workers", "4", "-output", output, dataDir, config)
+	if code != exitError {
+		t.Fatalf("с -fail-fast код %d; ожидался %d\n%s", code, exitError, out)
+	}
+	if !strings.Contains(out, broken) {
+		t.Errorf("ошибка чтения %s не выведена:\n%s", broken, out)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("с -fail-fast создан файл результата: %v", err)
+	}
+}
+
+func TestReadDocumentWaitsForOpenFileSlot(t *testing.T) {
+	saved := openFiles
+	openFiles = make(chan struct{}, 2)
+	defer func() { openFiles = saved }()
+	filename := writeTestFile(t, t.TempDir(), "d.xml", goodsDocument(1))
+
+	openFiles <- struct{}{}
+	openFiles <- struct{}{}
+	done := make(chan error, 1)
+	go func() {
+		_, err := readDocument(filename, &Config{})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("файл прочитан сверх лимита -max-open-files: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-openFiles
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("файл не прочитан после освобождения места")
+	}
+	if len(openFiles) != 1 {
+		t.Errorf("занято мест %d после чтения; ожидалось 1", len(openFiles))
+	}
+}
+
+func TestRunGzipOutputRoundTrip(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "d.xml", goodsDocument(1, 2, 3))
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+	plain := filepath.Join(outDir, "result.csv")
+	compressed := filepath.Join(outDir, "result.csv.gz")
+
+	if code, out := runArgs(t, "-output", plain, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+	if code, out := runArgs(t, "-output", compressed, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+
+	file, err := os.Open(compressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("распакованный результат %q; ожидался %q", got, want)
+	}
+}
+
+func TestReadDocumentRetriesTransientErrors(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "late.xml")
+	if _, err := readDocument(filename, &Config{}); err == nil {
+		t.Fatal("без -read-retries чтение отсутствующего файла прошло")
+	}
+
+	created := make(chan error, 1)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		created <- os.WriteFile(filename, []byte(goodsDocument(1)), 0o644)
+	}()
+	doc, err := readDocument(filename, &Config{ReadRetries: 3})
+	if err := <-created; err != nil {
+		t.Fatal(err)
+	}
+	if err != nil {
+		t.Fatalf("чтение с повтором: %v", err)
+	}
+	if doc.FindElement("//GoodsNumeric") == nil {
+		t.Error("прочитан неполный документ")
+	}
+
+	broken := writeTestFile(t, dir, "broken.xml", "<ESADout_CU>")
+	start := time.Now()
+	if _, err := readDocument(broken, &Config{ReadRetries: 3}); err == nil {
+		t.Error("ошибка разбора XML не возвращена")
+	}
+	if elapsed := time.Since(start); elapsed >= readRetryBackoff {
+		t.Errorf("ошибка разбора XML повторялась (%v)", elapsed)
+	}
+}
+
+func TestRunPerFileNameCollision(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1))
+	writeTestFile(t, dataDir, "a.XML", goodsDocument(2))
+	outDir := t.TempDir()
+
+	code, out := runArgs(t, "-per-file", "-out-dir", outDir, dataDir, filepath.Join(dataDir, "missing.cfg"))
+	if code != exitError {
+		t.Fatalf("код %d; ожидался %d\n%s", code, exitError, out)
+	}
+	if !strings.Contains(out, "-per-file") {
+		t.Errorf("нет сообщения о совпадении имён:\n%s", out)
+	}
+	if entries, err := os.ReadDir(outDir); err != nil || len(entries) != 0 {
+		t.Errorf("записаны файлы результата: %v, %v", entries, err)
+	}
+}
+
+func TestRunChecksumSidecar(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "d.xml", goodsDocument(1, 2))
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+
+	tests := []struct {
+		algorithm string
+		output    string
+		sum       func([]byte) []byte
+	}{
+		{checksumSHA256, "result.csv", func(data []byte) []byte { sum := sha256.Sum256(data); return sum[:] }},
+		{checksumMD5, "result.csv.gz", func(data []byte) []byte { sum := md5.Sum(data); return sum[:] }},
+	}
+	for _, tt := range tests {
+		output := filepath.Join(outDir, tt.output)
+		if code, out := runArgs(t, "-checksum", tt.algorithm, "-output", output, dataDir, config); code != exitOK {
+			t.Fatalf("%s: код %d\n%s", tt.algorithm, code, out)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sidecar, err := os.ReadFile(output + "." + tt.algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := fmt.Sprintf("%x  %s\n", tt.sum(data), tt.output); string(sidecar) != want {
+			t.Errorf("%s: файл суммы %q; ожидался %q", tt.algorithm, sidecar, want)
+		}
+	}
+}
+
+func TestCheckFieldOptions(t *testing.T) {
+	tests := []struct {
+		options FieldOptions
+		ok      bool
+	}{
+		{FieldOptions{"type": "number", "round": "2"}, true},
+		{FieldOptions{"type": "number", "round": "0"}, true},
+		{FieldOptions{"translit": "true"}, true},
+		{FieldOptions{"type": "number", "round": "-1"}, false},
+		{FieldOptions{"type": "number", "round": "abc"}, false},
+		{FieldOptions{"max-depth": "1"}, true},
+		{FieldOptions{"max-depth": "0"}, false},
+		{FieldOptions{"max-depth": "deep"}, false},
+		{FieldOptions{"maxlen": "10", "on-overflow": "error", "ellipsis": "..."}, true},
+		{FieldOptions{"maxlen": "0"}, false},
+		{FieldOptions{"maxlen": "10", "on-overflow": "cut"}, false},
+		{FieldOptions{"maxlen": "3", "ellipsis": "..."}, false},
+		{FieldOptions{"on-missing": "skip-record"}, true},
+		{FieldOptions{"on-missing": "drop"}, false},
+		{FieldOptions{"emit": "tagname"}, true},
+		{FieldOptions{"emit": "name"}, false},
+		{FieldOptions{"excel-text": "true"}, true},
+		{FieldOptions{"excel-text": "yes"}, false},
+		{FieldOptions{"type": "number", "decimal": ","}, true},
+		{FieldOptions{"type": "number", "decimal": ";"}, false},
+		{FieldOptions{"type": "number", "decimal": ",", "group": ","}, false},
+		{FieldOptions{"join-newlines": `" / "`}, true},
+		{FieldOptions{"join-newlines": `"\q"`}, false},
+		{FieldOptions{"type": "int", "round": "floor"}, true},
+		{FieldOptions{"type": "int"}, true},
+		{FieldOptions{"type": "int", "round": "2"}, false},
+	}
+	for _, tt := range tests {
+		err := checkFieldOptions(map[string]FieldOptions{"Колонка": tt.options})
+		if (err == nil) != tt.ok {
+			t.Errorf("checkFieldOptions(%v) = %v; ожидалось ok=%v", tt.options, err, tt.ok)
+		}
+	}
+}
+
+func TestRunTSVExcelBytes(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	config := writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\nGoodsDescription=Название\n")
+	output := filepath.Join(t.TempDir(), "result.csv")
+	if code, out := runArgs(t, "-no-defaults", "-format", "tsv-excel", "-output", output, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0xFF, 0xFE}
+	for _, r := range "Номер\tНазвание\r\n1\tТовар 1\r\n2\tТовар 2\r\n" {
+		want = append(want, byte(r), byte(r>>8))
+	}
+	if !bytes.Equal(data, want) {
+		t.Errorf("байты результата:\n% x\nожидалось:\n% x", data, want)
+	}
+}
+
+func TestRunNoNumberAutodetect(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", "<ESADout_CU><ESADout_CUGoods><GoodsNumeric>1</GoodsNumeric><CustomsCost>1 234,5</CustomsCost></ESADout_CUGoods></ESADout_CU>")
+	output := filepath.Join(t.TempDir(), "result.csv")
+	config := writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\nCustomsCost=Стоимость;type=number\n")
+	if code, _ := runArgs(t, "-no-defaults", "-no-number-autodetect", "-output", output, dataDir, config); code != exitError {
+		t.Errorf("type=number без decimal=: код %d; ожидалось %d", code, exitError)
+	}
+
+	config = writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\nCustomsCost=Стоимость;type=number;decimal=,;group=\" \";round=2\n")
+	if code, out := runArgs(t, "-no-defaults", "-no-number-autodetect", "-output", output, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+	if lines := readLines(t, output); len(lines) != 2 || lines[1] != "1;1234,50" {
+		t.Errorf("результат %q", lines)
+	}
+}
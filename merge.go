@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+	unicodeenc "golang.org/x/text/encoding/unicode"
+)
+
+var delimiterCandidates = []rune{';', ',', '\t', '|'}
+
+func detectDelimiter(line string, fallback rune) rune {
+	counts := make(map[rune]int)
+	quoted := false
+	for _, r := range line {
+		if r == '"' {
+			quoted = !quoted
+			continue
+		}
+		if !quoted {
+			counts[r]++
+		}
+	}
+
+	best, bestCount := fallback, counts[fallback]
+	for _, candidate := range delimiterCandidates {
+		if counts[candidate] > bestCount {
+			best, bestCount = candidate, counts[candidate]
+		}
+	}
+	return best
+}
+
+func readCSVRecords(filename string, config *Config, delimiter rune) ([]string, []Record, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var in io.Reader = file
+	switch outputEncoding(config) {
+	case encodingCP1251:
+		in = charmap.Windows1251.NewDecoder().Reader(file)
+	case encodingUTF16LE:
+		in = unicodeenc.UTF16(unicodeenc.LittleEndian, unicodeenc.UseBOM).NewDecoder().Reader(file)
+	}
+	buffered := bufio.NewReader(in)
+	firstLine, err := buffered.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, nil, err
+	}
+	firstLine = strings.TrimPrefix(firstLine, "\uFEFF")
+	skipped := 0
+	skip := func(line string) bool {
+		if skipped < len(config.Preamble) && strings.TrimRight(line, "\r\n") == config.Preamble[skipped] {
+			skipped++
+			return true
+		}
+		skipped = len(config.Preamble)
+		return strings.HasPrefix(line, metadataPrefix)
+	}
+	for skip(firstLine) {
+		if err == io.EOF {
+			return nil, nil, nil
+		}
+		if firstLine, err = buffered.ReadString('\n'); err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+	}
+	if delimiter == 0 {
+		delimiter = detectDelimiter(firstLine, config.Delimiter)
+		verbosef("Разделитель файла %s: %q\n", filename, delimiter)
+	}
+	in = io.MultiReader(strings.NewReader(firstLine), buffered)
+	reader := csv.NewReader(in)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+
+	headers, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		record := make(Record, len(headers))
+		for i, header := range headers {
+			if i < len(row) {
+				record[header] = excelTextValue(row[i])
+			}
+		}
+		records = append(records, record)
+	}
+	return headers, records, nil
+}
+
+func mergeCSVFiles(inputs []string, filename string, config *Config, delimiter rune) error {
+	mergeConfig := *config
+	mergeConfig.FieldOrder = nil
+	mergeConfig.FieldOptions = nil
+
+	var records []Record
+	for _, input := range inputs {
+		headers, recs, err := readCSVRecords(input, config, delimiter)
+		if err != nil {
+			return fmt.Errorf(tr("ошибка при чтении CSV файла %s: %w"), input, err)
+		}
+		for _, header := range headers {
+			if !slices.Contains(mergeConfig.FieldOrder, header) {
+				mergeConfig.FieldOrder = append(mergeConfig.FieldOrder, header)
+			}
+		}
+		records = append(records, recs...)
+	}
+	if len(records) == 0 {
+		return errors.New(tr("во входных CSV файлах нет строк данных"))
+	}
+	return writeCSV(filename, records, &mergeConfig)
+}
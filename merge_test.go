@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCSVRecordsPreamble(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "a.csv")
+	data := "\uFEFFВыгрузка, отдел 5\r\n\r\nКод;Название\r\n0012;Стул\r\n"
+	if err := os.WriteFile(input, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{Delimiter: ';', Encoding: encodingUTF8, Preamble: []string{"Выгрузка, отдел 5", ""}}
+	headers, records, err := readCSVRecords(input, config, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 2 || headers[0] != "Код" || len(records) != 1 || records[0]["Код"] != "0012" {
+		t.Errorf("readCSVRecords = %q, %v", headers, records)
+	}
+
+	config.Preamble = []string{"Другая строка"}
+	if headers, _, err = readCSVRecords(input, config, ';'); err != nil {
+		t.Fatal(err)
+	}
+	if headers[0] != "Выгрузка, отдел 5" {
+		t.Errorf("заголовок %q; ожидалась первая строка файла", headers[0])
+	}
+}
+
+func TestMergeCSVFiles(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{Delimiter: ';', Encoding: encodingUTF8}
+	first := writeTestFile(t, dir, "a.csv", "Код;Название\n1;Стул\n")
+	second := writeTestFile(t, dir, "b.csv", "Код,Цена\n2,10\n")
+	output := filepath.Join(dir, "merged.csv")
+
+	if err := mergeCSVFiles([]string{first, second}, output, config, 0); err != nil {
+		t.Fatal(err)
+	}
+	lines := readLines(t, output)
+	want := []string{"Код;Название;Цена", "1;Стул;", "2;;10"}
+	if len(lines) != len(want) {
+		t.Fatalf("строки %q; ожидались %q", lines, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("строка %d = %q; ожидалась %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestReadCSVRecordsMetadata(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "a.csv")
+	data := "\uFEFFВыгрузка\r\n# xml_to_csv v1.4.0\r\n# files: 1\r\n# config: Code=Код, товара\r\nКод;Название\r\n0012;Стул\r\n"
+	if err := os.WriteFile(input, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{Delimiter: ',', Preamble: []string{"Выгрузка", "# xml_to_csv v1.5.0"}}
+	headers, records, err := readCSVRecords(input, config, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 2 || headers[0] != "Код" || len(records) != 1 || records[0]["Название"] != "Стул" {
+		t.Errorf("readCSVRecords = %q, %v", headers, records)
+	}
+}
+
+func TestMergeCSVFilesExcelText(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "a.csv")
+	if err := os.WriteFile(input, []byte("Код;Название\n\"=\"\"0012\"\"\";Стул\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	config := &Config{
+		Delimiter:    ';',
+		Encoding:     encodingUTF8,
+		FieldOptions: map[string]FieldOptions{"Код": {"excel-text": "true"}},
+	}
+	headers, records, err := readCSVRecords(input, config, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 2 || len(records) != 1 || records[0]["Код"] != "0012" {
+		t.Fatalf("readCSVRecords = %v, %v", headers, records)
+	}
+
+	output := filepath.Join(dir, "merged.csv")
+	if err := mergeCSVFiles([]string{input}, output, config, 0); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "Код;Название\n0012;Стул\n"; string(data) != want {
+		t.Errorf("результат %q; ожидалось %q", data, want)
+	}
+}
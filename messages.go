@@ -0,0 +1,482 @@
+package main
+
+import "strings"
+
+var lang = "ru"
+
+var englishMessages = map[string]string{
+	"путь к файлу конфигурации %s — каталог, а не файл":                            "config path %s is a directory, not a file",
+	"ошибка при чтении файла конфигурации %s: %w":                                  "error reading config file %s: %w",
+	"ошибка при чтении -config-json: %w":                                           "error reading -config-json: %w",
+	"Недопустимое значение excel-text=%q для колонки %q: ожидается true или false": "Invalid excel-text=%q for column %q: expected true or false",
+	"записать блоки в отдельные файлы по значению атрибута блока (или ближайшего предка), добавляя его к имени файла результата": "write blocks to separate files by the value of a block (or nearest ancestor) attribute, appending it to the output file name",
+	"Флаг -also-output несовместим с -partition-by-attr": "Flag -also-output cannot be used with -partition-by-attr",
+	"значения атрибута %q и %q дают одно имя файла %q":   "attribute values %q and %q produce the same file name %q",
+	"не угадывать разделители разрядов и дробной части чисел: колонкам type=number и type=int нужен параметр decimal= (и group=, если есть разделители разрядов), остальные значения считаются числами только в виде 1234.5": "do not guess thousands and decimal separators: type=number and type=int columns need a decimal= option (and group= if there are thousands separators), other values count as numbers only in the form 1234.5",
+	"Флаг -group-sep несовместим с -no-number-autodetect, задайте group= у колонок":                                       "Flag -group-sep cannot be used with -no-number-autodetect, set group= on the columns",
+	"Недопустимый формат числа колонки %q: %v":                                                                            "Invalid number format for column %q: %v",
+	"С -no-number-autodetect колонке %q с type=%s нужен параметр decimal=":                                                "With -no-number-autodetect, column %q with type=%s needs a decimal= option",
+	"decimal=%q: ожидается . или ,":                                                                                       "decimal=%q: expected . or ,",
+	"group= задаётся только вместе с decimal=":                                                                            "group= can only be set together with decimal=",
+	"group=%q совпадает с decimal=%q":                                                                                     "group=%q overlaps decimal=%q",
+	"недопустимый разделитель разрядов group=%q":                                                                          "invalid thousands separator group=%q",
+	"максимальное время загрузки одного XML файла по адресу http:// или https://":                                         "maximum time to download one XML file from an http:// or https:// address",
+	"заголовок запроса при загрузке XML по HTTP, \"ИМЯ: ЗНАЧЕНИЕ\" (можно указать несколько раз)":                         "request header for downloading XML over HTTP, \"NAME: VALUE\" (can be repeated)",
+	"пользователь и пароль для загрузки XML по HTTP (Basic), ПОЛЬЗОВАТЕЛЬ:ПАРОЛЬ; без пароля он берётся из переменной %s": "user and password for downloading XML over HTTP (Basic), USER:PASSWORD; without a password it is taken from the %s variable",
+	"Время -http-timeout должно быть положительным:":                                                                      "-http-timeout must be positive:",
+	"Ошибка в -http-header:": "Error in -http-header:",
+	"Ошибка в -http-user:":   "Error in -http-user:",
+	"Флаг %s несовместим с загрузкой XML по адресу http:// или https://\n": "Flag %s cannot be used with XML downloaded from an http:// or https:// address\n",
+	"ожидается ИМЯ: ЗНАЧЕНИЕ, получено %q":                                 "expected NAME: VALUE, got %q",
+	"ожидается ПОЛЬЗОВАТЕЛЬ:ПАРОЛЬ или пароль в переменной %s":             "expected USER:PASSWORD or a password in the %s variable",
+	"слишком много перенаправлений":                                        "too many redirects",
+	"перенаправление с https на %s запрещено":                              "redirect from https to %s is not allowed",
+	"сервер ответил %s":                                                    "server responded %s",
+	"записать в начало результата сведения о запуске: версию программы, время, каталог входных файлов, их число и итоговую конфигурацию": "write run details at the start of the output: program version, time, input directory, file count and the effective configuration",
+	"завершиться ошибкой, не записывая результат, если в нём больше N записей (0 — без ограничения)":                                     "fail without writing the output if it has more than N records (0 means no limit)",
+	"в результате %d записей, больше допустимого -max-rows %d; результат не записан":                                                     "the output has %d records, more than the -max-rows limit of %d; output not written",
+	"Число записей -max-rows не может быть отрицательным:":                                                                               "The -max-rows record count cannot be negative:",
+	"записать в отдельный CSV суммы перечисленных через запятую колонок по каждому XML файлу и общий итог":                               "write sums of the comma-separated columns for each XML file and a grand total to a separate CSV",
+	"файл отчёта -file-totals (по умолчанию имя результата с суффиксом _totals)":                                                         "-file-totals report file (defaults to the output name with the _totals suffix)",
+	"Ошибка при записи итогов по файлам:":                                                                                                "Error writing per-file totals:",
+	"писать ход обработки и итоги в JSON Lines в открытый дескриптор файла с этим номером (0 — не писать)":                               "write progress and summary as JSON Lines to the open file descriptor with this number (0 disables)",
+	"Номер дескриптора -status-fd не может быть отрицательным:":                                                                          "The -status-fd descriptor number cannot be negative:",
+	"недопустимый дескриптор -status-fd":                                                                                                 "invalid -status-fd descriptor",
+	"дескриптор -status-fd не открыт":                                                                                                    "the -status-fd descriptor is not open",
+	"сравнивать ключи -group-by, -mark-duplicates, -distinct и -id-column после преобразований через запятую: casefold (без учёта регистра), nfc (в форме Unicode NFC); значения в результате не меняются": "compare -group-by, -mark-duplicates, -distinct and -id-column keys after comma-separated transforms: casefold (case-insensitive), nfc (Unicode NFC form); output values are unchanged",
+	"Ошибка в -dedupe-normalize:": "Error in -dedupe-normalize:",
+	"неизвестное преобразование ключа %q, ожидается casefold или nfc":                                                                  "unknown key transform %q, expected casefold or nfc",
+	"префикс пространства имён в именах колонок -auto-map: strip (только локальное имя) или keep (ns:Tag)":                             "namespace prefix in -auto-map column names: strip (local name only) or keep (ns:Tag)",
+	"что делать, если теги с разными префиксами дают одну колонку -auto-map: first (взять первый), suffix (добавить _2, _3) или error": "what to do when tags with different prefixes give the same -auto-map column: first (take the first), suffix (append _2, _3) or error",
+	"Неизвестный режим -auto-map-prefix:":    "Unknown -auto-map-prefix mode:",
+	"Неизвестный режим -auto-map-collision:": "Unknown -auto-map-collision mode:",
+	"в файле %s в блоке %s: %w":              "in file %s in block %s: %w",
+	"теги %s и %s дают одну колонку %q":      "tags %s and %s give the same column %q",
+	"пропустить каждый записываемый файл результата через внешнюю команду (через sh -c или cmd /C): команда читает данные из stdin, её stdout записывается в файл": "pipe every written output file through an external command (via sh -c or cmd /C): the command reads the data from stdin and its stdout is written to the file",
+	"ошибка при запуске -post-command: %w":               "error starting -post-command: %w",
+	"команда -post-command завершилась с ошибкой: %w":    "the -post-command command failed: %w",
+	"ошибка при передаче результата в -post-command: %w": "error passing the output to -post-command: %w",
+	"вместо удаления повторов отметить их: keys=Колонка1,Колонка2 добавляет колонки %s (номер группы повторов) и %s (число записей с тем же ключом)": "mark duplicates instead of removing them: keys=Column1,Column2 adds columns %s (duplicate group number) and %s (number of records with the same key)",
+	"не указаны колонки ключа в %q, ожидается keys=Колонка1,Колонка2":                                                                                "no key columns in %q, expected keys=Column1,Column2",
+	"Групп повторов по колонкам %s в %s: %d\n":                                                    "Duplicate groups by columns %s in %s: %d\n",
+	"Недопустимое значение round=%q для колонки %q с type=int: ожидается nearest, floor или ceil": "Invalid value round=%q for column %q with type=int: expected nearest, floor or ceil",
+	"В файле %s не удалось разобрать как число значений колонки %q с type=int: %d\n":              "In file %s, values of column %q with type=int that could not be parsed as a number: %d\n",
+	"ВНИМАНИЕ: файл конфигурации %s не найден, используется встроенное сопоставление для таможенных деклараций, но в файле %s нет ни одного блока %s.\nУкажите свою конфигурацию вторым аргументом или через -config-json, либо запустите с -no-defaults -auto-map.\n": "WARNING: configuration file %s not found, using the built-in mapping for customs declarations, but file %s has no %s blocks.\nPass your own configuration as the second argument or via -config-json, or run with -no-defaults -auto-map.\n",
+	"Недопустимое значение join-newlines=%s для колонки %q: %v": "Invalid value join-newlines=%s for column %q: %v",
+	"дополнительно записать те же записи в файл другого формата: ФОРМАТ=ФАЙЛ, например ndjson=out.ndjson (можно указать несколько раз)": "also write the same records to a file in another format: FORMAT=FILE, e.g. ndjson=out.ndjson (can be repeated)",
+	"ожидается ФОРМАТ=ФАЙЛ, получено %q":             "expected FORMAT=FILE, got %q",
+	"неизвестный формат %q в %q":                     "unknown format %q in %q",
+	"формат parquet несовместим со сжатием: %q":      "the parquet format cannot be compressed: %q",
+	"Ошибка в -also-output:":                         "Error in -also-output:",
+	"Флаг -also-output несовместим с -per-file":      "The -also-output flag cannot be combined with -per-file",
+	"Флаг -also-output несовместим с -chunk-size":    "The -also-output flag cannot be combined with -chunk-size",
+	"Флаг -also-output несовместим с -zip-output":    "The -also-output flag cannot be combined with -zip-output",
+	"Флаг -also-output несовместим с -transpose":     "The -also-output flag cannot be combined with -transpose",
+	"Файл %s указан и в -output, и в -also-output\n": "File %s is given both in -output and in -also-output\n",
+	"упорядочить колонки результата по алфавиту их имён вместо порядка конфигурации":                                      "sort output columns alphabetically by name instead of the configured order",
+	"порядок имён для -sort-columns: bytes (по кодам символов) или ru (русский алфавит, ё рядом с е, без учёта регистра)": "name order for -sort-columns: bytes (by character codes) or ru (Russian alphabet, ё next to е, case-insensitive)",
+	"Неизвестный порядок -column-collation:":                                      "Unknown -column-collation order:",
+	"Флаг -sort-columns несовместим с -columns, -columns-from и -template-header": "The -sort-columns flag cannot be combined with -columns, -columns-from and -template-header",
+	"пропускать XML файлы, изменённые позже, чем указанное время назад, например 30s (файлы, которые ещё записываются)": "skip XML files modified more recently than the given time ago, e.g. 30s (files still being written)",
+	"Возраст -min-age не может быть отрицательным:":      "The -min-age age cannot be negative:",
+	"Файл %s изменён %v назад, пропущен (-min-age %v)\n": "File %s modified %v ago, skipped (-min-age %v)\n",
+	"Все файлы изменены менее %v назад\n":                "All files were modified less than %v ago\n",
+	"добавить колонку с суммой и кодом валюты, ИМЯ=КОЛОНКА_СУММЫ+КОЛОНКА_ВАЛЮТЫ (можно указать несколько раз)": "add a column with an amount and currency code, NAME=AMOUNT_COLUMN+CURRENCY_COLUMN (repeatable)",
+	"шаблон значения -money-column с подстановками {amount} и {currency}":                                      "-money-column value pattern with {amount} and {currency} placeholders",
+	"запись суммы в -money-column: plain (1234.50), ru (1 234,50) или en (1,234.50)":                           "amount style in -money-column: plain (1234.50), ru (1 234,50) or en (1,234.50)",
+	"число знаков после запятой в сумме -money-column":                                                         "number of decimal places in the -money-column amount",
+	"Недопустимый -money-column, ожидается ИМЯ=КОЛОНКА_СУММЫ+КОЛОНКА_ВАЛЮТЫ:":                                  "Invalid -money-column, expected NAME=AMOUNT_COLUMN+CURRENCY_COLUMN:",
+	"Неизвестная запись суммы -money-locale:":                                                                  "Unknown -money-locale amount style:",
+	"Число знаков -money-places не может быть отрицательным:":                                                  "The -money-places count cannot be negative:",
+	"проверить, что все теги и атрибуты конфигурации встречаются в XML файле или схеме XSD, и выйти":           "check that all tags and attributes of the configuration occur in an XML file or XSD schema, and exit",
+	"Тег":     "Tag",
+	"Атрибут": "Attribute",
+	"%s %q из %q не встречается в %s, возможно, имелся в виду %q\n":                                                                   "%s %q from %q does not occur in %s, did you mean %q?\n",
+	"%s %q из %q не встречается в %s\n":                                                                                               "%s %q from %q does not occur in %s\n",
+	"Все теги и атрибуты конфигурации найдены в %s\n":                                                                                 "All configuration tags and attributes found in %s\n",
+	"Недопустимое значение emit=%q для колонки %q: ожидается text или tagname":                                                        "Invalid emit=%q for column %q: expected text or tagname",
+	"вывести по каждому файлу и всего число найденных блоков и извлечённых записей":                                                   "print the number of blocks found and records extracted per file and in total",
+	"%s: блоков %s %d, записей %d (не вошло: %d)\n":                                                                                   "%s: %s blocks %d, records %d (not included: %d)\n",
+	"%s: блоков %s %d, записей %d\n":                                                                                                  "%s: %s blocks %d, records %d\n",
+	"Всего блоков %s: %d, извлечено записей: %d, записано: %d\n":                                                                      "Total %s blocks: %d, records extracted: %d, written: %d\n",
+	"добавить колонку @атрибут для каждого атрибута блока":                                                                            "add an @attribute column for every attribute of the block",
+	"вместе с -flatten-attributes добавить колонки тег@атрибут для атрибутов конечных элементов блока":                                "together with -flatten-attributes add tag@attribute columns for attributes of the block's leaf elements",
+	"записать в CSV файл для каждой записи путь элемента, из которого взята каждая колонка, или MISSING":                              "write to a CSV file, for each record, the path of the element each column was taken from, or MISSING",
+	"ошибка при создании файла трассировки: %w":                                                                                       "error creating trace file: %w",
+	"ошибка при записи файла трассировки: %w":                                                                                         "error writing trace file: %w",
+	"что делать, если элемент колонки не найден в блоке: empty (пустое значение), skip-record (пропустить запись) или error (ошибка)": "what to do when a column's element is not found in a block: empty (empty value), skip-record (drop the record) or error (fail)",
+	"Неизвестный режим -on-missing:":                                                                                                  "Unknown -on-missing mode:",
+	"Недопустимое значение on-missing=%q для колонки %q: ожидается empty, skip-record или error":                                      "Invalid on-missing=%q for column %q: expected empty, skip-record or error",
+	"в файле %s в блоке %s не найден элемент колонки %q":                                                                              "file %s, block %s: element for column %q not found",
+	"В файле %s пропущено записей без элемента колонки %q: %d\n":                                                                      "File %s: records skipped without an element for column %q: %d\n",
+	"файл может содержать несколько XML документов подряд, каждый со своим объявлением <?xml ...?>":                                   "a file may contain several XML documents in a row, each with its own <?xml ...?> declaration",
+	"документ %d: %w": "document %d: %w",
+	"показать первые N записей таблицей и выйти, не записывая результат":                                                  "show the first N records as a table and exit without writing output",
+	"Число записей -head не может быть отрицательным:":                                                                    "The -head record count cannot be negative:",
+	"Первые записи: %d (прочитано файлов %d из %d)\n":                                                                     "First records: %d (files read: %d of %d)\n",
+	"кодировка результата: utf8, utf8-bom, cp1251 или utf16le (по умолчанию cp1251 в Windows, utf8 в остальных системах)": "output encoding: utf8, utf8-bom, cp1251 or utf16le (default cp1251 on Windows, utf8 elsewhere)",
+	"формат результата: csv, xml, json, ndjson, parquet или tsv-excel (CSV с табуляцией в UTF-16LE для Excel)":            "output format: csv, xml, json, ndjson, parquet or tsv-excel (tab-separated UTF-16LE CSV for Excel)",
+	"Формат tsv-excel задаёт кодировку utf16le и разделитель \\t, их нельзя изменить":                                     "The tsv-excel format sets encoding utf16le and delimiter \\t, they cannot be changed",
+	"вывести черновик конфигурации, подобранный по XML файлу, и выйти":                                                    "print a starter configuration guessed from an XML file and exit",
+	"Ошибка при подборе конфигурации:":                                                                                    "Error inferring configuration:",
+	"в файле %s нет корневого элемента":                                                                                   "file %s has no root element",
+	"не найдено элементов с вложенными элементами, блок не определить":                                                    "no elements with child elements found, cannot determine the block",
+	"# Черновик конфигурации по файлу %s\n# Блок %s: найдено %d, полей %d. Проверьте блок и имена колонок.\n":             "# Starter configuration for file %s\n# Block %s: %d found, %d fields. Check the block and column names.\n",
+	"замерить время разбора каждого файла и вывести N самых медленных":                                                    "measure parse time of each file and print the N slowest",
+	"записать время разбора всех файлов в CSV файл, от самых медленных":                                                   "write parse times of all files to a CSV file, slowest first",
+	"Число файлов -timings не может быть отрицательным:":                                                                  "The -timings file count cannot be negative:",
+	"Ошибка при записи времени разбора:":                                                                                  "Error writing parse timings:",
+	"Самые медленные файлы (%d из %d):\n":                                                                                 "Slowest files (%d of %d):\n",
+	"символы-разделители разрядов чисел, удаляемые перед разбором, например ' или .":                                      "number digit grouping characters removed before parsing, e.g. ' or .",
+	"Недопустимый разделитель разрядов -group-sep:":                                                                       "Invalid -group-sep grouping separator:",
+	"не создавать файл результата без записей (даже с -header-on-empty) и завершаться с кодом 3 или 4":                    "do not create a result file without records (even with -header-on-empty) and exit with code 3 or 4",
+	"Все записи отброшены при отборе: %d\n":                                                                               "All records were filtered out: %d\n",
+	"Нет данных, файл результата не создаётся":                                                                            "No data, the result file is not created",
+	"запись %d файла %s: %w":                                                                             "record %d of file %s: %w",
+	"тип %s не является структурой":                                                                      "type %s is not a struct",
+	"колонка %q: значение %q не является датой":                                                          "column %q: value %q is not a date",
+	"колонка %q: значение %q не является целым числом":                                                   "column %q: value %q is not an integer",
+	"колонка %q: значение %q не является числом":                                                         "column %q: value %q is not a number",
+	"колонка %q: тип поля %s не поддерживается":                                                          "column %q: field type %s is not supported",
+	"команда, выполняемая после успешной записи результата (через sh -c или cmd /C)":                     "command run after the result is written successfully (via sh -c or cmd /C)",
+	"адрес, на который после успешной записи результата отправляется POST с итогами в JSON":              "URL that receives a POST with a JSON summary after the result is written successfully",
+	"Команда -on-complete завершилась с ошибкой: %v\n":                                                   "The -on-complete command failed: %v\n",
+	"Ошибка при отправке -webhook: %v\n":                                                                 "Error sending -webhook: %v\n",
+	"из одноимённых элементов с атрибутом языка брать элемент на этом языке, например ru (иначе первый)": "among same-named elements with a language attribute take the one in this language, e.g. ru (otherwise the first)",
+	"атрибут языка для -lang-attr":                                                                       "language attribute for -lang-attr",
+	"записать в CSV файл показатели качества каждой колонки: число строк, заполненных, пустых и различных значений, минимум и максимум чисел": "write per-column quality metrics to a CSV file: rows, filled, empty and distinct values, numeric minimum and maximum",
+	"Ошибка при записи показателей качества:":                                           "Error writing quality metrics:",
+	"Используется %s рядом с программой\n":                                              "Using %s next to the program\n",
+	"записать файлы результата записями одного ZIP архива вместо отдельных файлов":      "write the result files as entries of one ZIP archive instead of separate files",
+	"Флаг -zip-output несовместим с -gzip":                                              "Flag -zip-output cannot be combined with -gzip",
+	"Флаг -zip-output несовместим с -checksum":                                          "Flag -zip-output cannot be combined with -checksum",
+	"Флаг -zip-output несовместим с -merge-csv":                                         "Flag -zip-output cannot be combined with -merge-csv",
+	"Ошибка при создании архива результата:":                                            "Error creating the result archive:",
+	"Файл %s уже есть в архиве %s\n":                                                    "File %s is already in archive %s\n",
+	"Ошибка при записи архива результата:":                                              "Error writing the result archive:",
+	"Записан архив %s, файлов: %d\n":                                                    "Archive %s written, files: %d\n",
+	"ошибка при создании записи архива: %w":                                             "error creating an archive entry: %w",
+	"сообщить о непустых конечных элементах блоков, не сопоставленных ни одной колонке": "report non-empty leaf elements of blocks not mapped to any column",
+	"что делать при несопоставленных элементах -strict-mapping: warn или error":         "what to do with -strict-mapping unmapped elements: warn or error",
+	"Неизвестный режим -unmapped-mode:":                                                 "Unknown -unmapped-mode:",
+	"Непустой элемент %s не сопоставлен ни одной колонке (вхождений: %d)\n":             "Non-empty element %s is not mapped to any column (occurrences: %d)\n",
+	"Несопоставленных тегов: %d\n":                                                      "Unmapped tags: %d\n",
+	"пропустить первые N XML файлов (в порядке обработки)":                              "skip the first N XML files (in processing order)",
+	"порядок обработки XML файлов: name, mtime или mtime-desc (по умолчанию по имени в каждом каталоге, для -files — порядок списка)": "XML file processing order: name, mtime or mtime-desc (default: by name within each directory, list order for -files)",
+	"неизвестный порядок -order: %q (ожидается name, mtime или mtime-desc)":                                                           "unknown -order: %q (expected name, mtime or mtime-desc)",
+	"добавить колонку с составным ключом из колонок результата, ИМЯ=КОЛОНКА1+КОЛОНКА2 (можно указать несколько раз)":                  "add a composite key column built from result columns, NAME=COLUMN1+COLUMN2 (can be repeated)",
+	"разделитель частей -key-column":                             "separator between -key-column parts",
+	"Недопустимый -key-column, ожидается ИМЯ=КОЛОНКА1+КОЛОНКА2:": "Invalid -key-column, expected NAME=COLUMN1+COLUMN2:",
+	"обрабатывать только блоки с атрибутом, равным значению, АТРИБУТ=ЗНАЧЕНИЕ (можно указать несколько раз, должны выполняться все)": "process only blocks whose attribute equals the value, ATTRIBUTE=VALUE (can be repeated, all must match)",
+	"Недопустимый фильтр -block-filter, ожидается АТРИБУТ=ЗНАЧЕНИЕ:":                                                                 "Invalid -block-filter, expected ATTRIBUTE=VALUE:",
+	"  блок не подходит под -block-filter":                                              "  block does not match -block-filter",
+	"ошибка при записи файла результата: %w":                                            "error writing the output file: %w",
+	"размер буфера записи файла результата в байтах (0 — без буфера)":                   "output file write buffer size in bytes (0 — no buffer)",
+	"Размер буфера не может быть отрицательным:":                                        "Buffer size cannot be negative:",
+	"Недопустимое значение maxlen=%q для колонки %q":                                    "Invalid value maxlen=%q for column %q",
+	"Недопустимое значение on-overflow=%q для колонки %q: ожидается truncate или error": "Invalid value on-overflow=%q for column %q: expected truncate or error",
+	"Параметр ellipsis колонки %q не короче maxlen=%d":                                  "Option ellipsis of column %q is not shorter than maxlen=%d",
+	"в файле %s значение колонки %q длиннее maxlen=%d: %d символов":                     "in file %s the value of column %q is longer than maxlen=%d: %d characters",
+	"В файле %s обрезано до maxlen=%s значений колонки %q: %d\n":                        "In file %s values truncated to maxlen=%s in column %q: %d\n",
+	"сравнить результат с CSV файлом прошлого запуска по колонке -id-column и записать отчёт о добавленных, удалённых и изменённых строках": "compare the result with a previous run's CSV file by the -id-column column and write a report of added, removed and changed rows",
+	"файл отчёта -diff-against (по умолчанию имя результата с суффиксом _diff)":                                                             "-diff-against report file (default: result name with the _diff suffix)",
+	"Для -diff-against нужно указать -id-column":                          "-diff-against requires -id-column",
+	"в файле %s нет колонки %q":                                           "file %s has no column %q",
+	"Сравнение с %s: добавлено %d, удалено %d, изменено %d, отчёт в %s\n": "Compared with %s: added %d, removed %d, changed %d, report in %s\n",
+	"разбирать XML с типичными ошибками: атрибуты без значения или кавычек, неэкранированный &":              "parse XML with common mistakes: attributes without value or quotes, unescaped &",
+	"сохранять разделы CDATA в колонке -with-raw, а не экранировать их содержимое":                           "keep CDATA sections in the -with-raw column instead of escaping their content",
+	"проверить, что в колонках через запятую записаны только числа, и сообщить о каждом нечисловом значении": "check that the comma-separated columns contain only numbers and report each non-numeric value",
+	"завершиться с ошибкой, если -validate-numeric нашёл нечисловые значения":                                "fail if -validate-numeric found non-numeric values",
+	"%s, строка %d, колонка %q: не число %q\n":                                                               "%s, row %d, column %q: not a number %q\n",
+	"Нечисловых значений: %d\n": "Non-numeric values: %d\n",
+	"вместо записей вывести отсортированные различные непустые значения одной колонки":                         "output the sorted distinct non-empty values of one column instead of records",
+	"Флаг -distinct несовместим с -columns, -columns-from и -template-header":                                  "Flag -distinct cannot be combined with -columns, -columns-from and -template-header",
+	"добавить колонку %s с путём исходного XML файла вместе с каталогом":                                       "add a %s column with the source XML file path including its directory",
+	"число потоков, формирующих строки CSV перед записью":                                                      "number of threads building CSV rows before writing",
+	"Число потоков формирования строк должно быть положительным:":                                              "Number of row-building threads must be positive:",
+	"вместо результата вывести для каждой записи, откуда взято значение каждой колонки (для небольших файлов)": "instead of the output, print for every record where each column value came from (for small inputs)",
+	"найдено в %s: %q":                         "found in %s: %q",
+	"элемент пуст: %s":                         "element is empty: %s",
+	"элемент не найден: %s":                    "element not found: %s",
+	"Файл %s, блок %d %s\n":                    "File %s, block %d %s\n",
+	"  блок пропущен правилом skip-if:%s=%s\n": "  block skipped by rule skip-if:%s=%s\n",
+	"  запись пуста и не попадает в результат": "  the record is empty and is not written",
+	"число элементов":                          "element count",
+	"вычислено":                                "computed",
+	"нет значения":                             "no value",
+	"  запись отброшена: %s\n":                 "  record rejected: %s\n",
+	"удалить колонки, значения которых во всех записях совпадают с более ранней колонкой":                         "drop columns whose values match an earlier column in every record",
+	"Колонка %q совпадает с колонкой %q и удалена\n":                                                              "Column %q matches column %q and was dropped\n",
+	"Удалено одинаковых колонок: %d\n":                                                                            "Identical columns dropped: %d\n",
+	"читать список XML файлов (по пути в строке) из файла или из стандартного ввода (-) вместо поиска в каталоге": "read the list of XML files (one path per line) from a file or standard input (-) instead of searching the directory",
+	"Список файлов -files пуст":                                          "The -files list is empty",
+	"ошибка при чтении списка файлов: %w":                                "error reading the file list: %w",
+	"считать значения только из пробельных символов пустыми и без -trim": "treat whitespace-only values as empty even without -trim",
+	"%v\n": "%v\n",
+	"дополнительно записывать предупреждения в файл JSON Lines":               "also write warnings to a JSON Lines file",
+	"ошибка при создании файла предупреждений: %w":                            "error creating warnings file: %w",
+	"ошибка при записи файла предупреждений: %w":                              "error writing warnings file: %w",
+	"Недопустимое значение max-depth=%q для колонки %q":                       "Invalid max-depth=%q for column %q",
+	"добавить первой колонку %s с номером строки, начиная с 1":                "add a leading %s column with the row number starting at 1",
+	"колонка с датой для -since и -until":                                     "date column for -since and -until",
+	"оставить записи с датой -date-column не раньше указанной (включительно)": "keep records whose -date-column date is not earlier than this (inclusive)",
+	"оставить записи с датой -date-column не позже указанной (включительно)":  "keep records whose -date-column date is not later than this (inclusive)",
+	"Флаги -since и -until требуют -date-column":                              "Flags -since and -until require -date-column",
+	"Ошибка в -since:": "Error in -since:",
+	"Ошибка в -until:": "Error in -until:",
+	"неверная дата %q": "invalid date %q",
+	"Отброшено записей вне периода или без даты в колонке %q: %d\n":                                                                                         "Records dropped outside the period or without a date in column %q: %d\n",
+	"Формат parquet несовместим со сжатием -gzip":                                                                                                           "Format parquet is incompatible with -gzip compression",
+	"ошибка при записи Parquet файла: %w":                                                                                                                   "error writing Parquet file: %w",
+	"В колонке %q записано пустыми значений неверного типа: %d\n":                                                                                           "Column %q: values of the wrong type written as empty: %d\n",
+	"пропускать XML файлы, корневой элемент которых называется иначе (локальное имя)":                                                                       "skip XML files whose root element has a different local name",
+	"Файл %s пропущен: корневой элемент %q, ожидается %q":                                                                                                   "File %s skipped: root element %q, expected %q",
+	"Пропущено файлов с другим корневым элементом: %d из %d\n":                                                                                              "Files skipped for a different root element: %d of %d\n",
+	"добавить колонку %s с именами предков блока от корня документа":                                                                                        "add a %s column with the names of the block ancestors from the document root",
+	"значения через запятую, заменяемые пустыми, например \"—,N/A,null\" (с учётом регистра)":                                                               "comma-separated values replaced with empty ones, e.g. \"—,N/A,null\" (case-sensitive)",
+	"конфигурация в виде JSON, например {\"block\":\"Item\",\"fields\":[{\"source\":\"Code\",\"column\":\"Код\"}]}; файл конфигурации при этом не читается": "configuration as JSON, e.g. {\"block\":\"Item\",\"fields\":[{\"source\":\"Code\",\"column\":\"Код\"}]}; the configuration file is not read",
+	"ошибка в -config-json на позиции %d: %w":                                                                                                               "error in -config-json at offset %d: %w",
+	"ошибка в -config-json: %w":                                                                     "error in -config-json: %w",
+	"ошибка в -config-json: лишние данные после объекта":                                            "error in -config-json: extra data after the object",
+	"ошибка в -config-json: недопустимый тег блока %q":                                              "error in -config-json: invalid block tag %q",
+	"ошибка в -config-json: у поля %d не задан source или column":                                   "error in -config-json: field %d has no source or column",
+	"ошибка в -config-json: недопустимые символы в поле %d":                                         "error in -config-json: invalid characters in field %d",
+	"ошибка в -config-json: недопустимые символы в параметре %q поля %d":                            "error in -config-json: invalid characters in option %q of field %d",
+	"ошибка в -config-json: недопустимое правило skip_if %d":                                        "error in -config-json: invalid skip_if rule %d",
+	"ошибка при закрытии временного файла %s: %w":                                                   "error closing temporary file %s: %w",
+	"ошибка при удалении временного файла: %w":                                                      "error removing temporary file: %w",
+	"хранить в памяти не более N записей, остальные сохранять во временный файл (0 — все в памяти)": "keep at most N records in memory and store the rest in a temporary file (0 keeps all in memory)",
+	"Порог -spill-threshold не может быть отрицательным:":                                           "-spill-threshold cannot be negative:",
+	"Флаги -two-pass и -spill-threshold несовместимы":                                               "Flags -two-pass and -spill-threshold are incompatible",
+	"Флаг %s несовместим с: %s":                                                                     "Flag %s is incompatible with: %s",
+	"ошибка при создании временного файла: %w":                                                      "error creating temporary file: %w",
+	"Записи сверх %d сохраняются во временный файл %s\n":                                            "Records beyond %d are stored in temporary file %s\n",
+	"ошибка при записи во временный файл: %w":                                                       "error writing temporary file: %w",
+	"ошибка при чтении временного файла: %w":                                                        "error reading temporary file: %w",
+	"Записей во временном файле: %d из %d\n":                                                        "Records in temporary file: %d of %d\n",
+	"добавить колонку для каждого конечного элемента блока с именем тега в качестве имени колонки":  "add a column for every leaf element of the block, named after its tag",
+	"в JSON и NDJSON записывать поля type=number строками":                                          "write type=number fields as strings in JSON and NDJSON",
+	"Формат %s записывается только в кодировке utf8\n":                                              "Format %s is written only in utf8\n",
+	"В колонке %q записано строкой нечисловых значений: %d\n":                                       "Column %q: non-numeric values written as strings: %d\n",
+	"ошибка при записи JSON файла: %w":                                                              "error writing JSON file: %w",
+	"читать XML файлы дважды: сначала собрать колонки, затем записывать строки без хранения всех записей в памяти": "read XML files twice: collect the columns first, then write rows without keeping all records in memory",
+	"Значение вне допустимого диапазона: %s\n":                                                          "Value out of the allowed range: %s\n",
+	"Первый проход: файлов %d, записей %d, колонок %d\n":                                                "First pass: files %d, records %d, columns %d\n",
+	"ошибка при повторном чтении файла %s: %w":                                                          "error re-reading file %s: %w",
+	"точный заголовок результата через разделитель полей, например \"a;b;c\"":                           "exact output header separated by the field delimiter, e.g. \"a;b;c\"",
+	"Флаг -template-header несовместим с -columns и -columns-from":                                      "Flag -template-header is incompatible with -columns and -columns-from",
+	"Ошибка в -template-header:":                                                                        "Error in -template-header:",
+	"Число пропускаемых файлов не может быть отрицательным:":                                            "Number of files to skip cannot be negative:",
+	"Все файлы пропущены: -skip-files %d, найдено файлов: %d\n":                                         "All files skipped: -skip-files %d, files found: %d\n",
+	"Пропущены файлы: %s\n":                                                                             "Skipped files: %s\n",
+	"ошибка в сопоставлении из файла %s: %w":                                                            "error in mapping from file %s: %w",
+	"путь etree к элементам с сопоставлениями внутри первого XML файла, например //MappingConfig/Field": "etree path to mapping elements inside the first XML file, e.g. //MappingConfig/Field",
+	"Добавлено сопоставлений из файла %s: %d\n":                                                         "Mappings added from file %s: %d\n",
+	"Файлы %s и %s дают один файл результата %s с -per-file":                                            "Files %s and %s produce the same output file %s with -per-file",
+	"Не удалось удалить временный файл состояния %s: %v\n":                                              "Failed to remove the temporary state file %s: %v\n",
+	"язык сообщений: ru или en":                                                                         "message language: ru or en",
+	"Неизвестный язык -lang:":                                                                           "Unknown -lang:",
+	"Колонка группировки %q не суммируется\n":                                                           "Grouping column %q is not summed\n",
+	"В колонке %q пропущено нечисловых значений при суммировании: %d\n":                                 "Column %q: non-numeric values skipped while summing: %d\n",
+	"Значение вне допустимого диапазона:":                                                               "Value out of the allowed range:",
+	"Значение %q колонки %q повторяется (записей: %d)\n":                                                "Value %q of column %q is repeated (records: %d)\n",
+	"Записей без значения в колонке %q: %d\n":                                                           "Records without a value in column %q: %d\n",
+	"Неуникальных значений в колонке %q: %d\n":                                                          "Non-unique values in column %q: %d\n",
+	"ошибка при чтении файла %s: %w":                                                                    "error reading file %s: %w",
+	"в файле %s нет блоков %s":                                                                          "file %s has no %s blocks",
+	"Поля блока %s в файле %s. Введите имя колонки или нажмите Enter, чтобы пропустить поле.\n":         "Fields of block %s in file %s. Enter a column name or press Enter to skip the field.\n",
+	"%s (пример: %q): ":                                                                          "%s (example: %q): ",
+	"ошибка при чтении ответа: %w":                                                               "error reading the answer: %w",
+	"не выбрано ни одного поля":                                                                  "no fields selected",
+	"Сохранить конфигурацию в файл (Enter — не сохранять): ":                                     "Save the configuration to file (Enter to skip): ",
+	"Файл %s уже существует, перезаписать? [y/N]: ":                                              "File %s already exists, overwrite? [y/N]: ",
+	"Конфигурация не сохранена":                                                                  "Configuration not saved",
+	"ошибка при сохранении конфигурации: %w":                                                     "error saving the configuration: %w",
+	"Конфигурация сохранена в":                                                                   "Configuration saved to",
+	"ошибка при чтении таблицы замен %s: %w":                                                     "error reading lookup table %s: %w",
+	"строка %d: ожидается две колонки, получено %d":                                              "line %d: expected two columns, got %d",
+	"Значение %q колонки %q не найдено в таблице замен\n":                                        "Value %q of column %q not found in the lookup table\n",
+	"Ошибка в строке %q: %v\n":                                                                   "Error in line %q: %v\n",
+	"Параметры полей во вложенном разделе не поддерживаются: %q\n":                               "Field options are not supported in a nested section: %q\n",
+	"Неизвестный параметр поля %q в строке %q\n":                                                 "Unknown field option %q in line %q\n",
+	"недопустимый разделитель: %q":                                                               "invalid delimiter: %q",
+	"Нажмите Enter для выхода...":                                                                "Press Enter to exit...",
+	"добавить колонку %s с путём блока в исходном XML":                                           "add a %s column with the block path in the source XML",
+	"не использовать встроенные сопоставления, только из файла конфигурации":                     "do not use built-in mappings, only those from the configuration file",
+	"сообщать о колонках, пустых в большинстве записей":                                          "report columns that are empty in most records",
+	"доля пустых значений (0..1), начиная с которой колонка считается пустой":                    "share of empty values (0..1) at which a column is considered empty",
+	"имя файла результата (по умолчанию result_<время>.csv)":                                     "output file name (default result_<time>.csv)",
+	"формат времени Go для имени файла по умолчанию":                                             "Go time layout for the default file name",
+	"обрезать пробельные символы по краям значений, включая содержимое CDATA":                    "trim whitespace around values, including CDATA content",
+	"каталог для файла результата; -output считается относительно него, если путь не абсолютный": "directory for the output file; -output is relative to it unless absolute",
+	"разделитель полей CSV (по умолчанию ';', \\t для табуляции)":                                "CSV field delimiter (default ';', \\t for tab)",
+	"вывести итоговую конфигурацию в формате файла конфигурации и выйти":                         "print the effective configuration in config file format and exit",
+	"число файлов, обрабатываемых одновременно":                                                  "number of files processed concurrently",
+	"максимальное время обработки всех файлов":                                                   "maximum time to process all files",
+	"прервать обработку при первой ошибке чтения файла":                                          "stop at the first file read error",
+	"отбросить записи с пустым значением колонки (можно указать несколько раз)":                  "drop records with an empty value in the column (repeatable)",
+	"записать отброшенные записи с причиной в отдельный CSV файл":                                "write dropped records with the reason to a separate CSV file",
+	"удалять из значений невидимые символы U+200B, U+200C, U+200D, U+2060 и U+FEFF":              "remove invisible characters U+200B, U+200C, U+200D, U+2060 and U+FEFF from values",
+	"максимальное число одновременно открытых XML файлов":                                        "maximum number of XML files open at once",
+	"раскрывать локальные включения xi:include":                                                  "expand local xi:include elements",
+	"отклонять файлы с объявлением DOCTYPE":                                                      "reject files with a DOCTYPE declaration",
+	"сжимать результат gzip (включается автоматически для -output с расширением .gz)":            "gzip the output (enabled automatically for -output ending in .gz)",
+	"записать описание колонок результата (имя, тип, заполненность) в JSON файл":                 "write a JSON description of the output columns (name, type, fill rate)",
+	"окончание строк результата: lf или crlf":                                                    "output line endings: lf or crlf",
+	"строка, записываемая перед заголовком как есть (можно указать несколько раз)":               "line written as is before the header (repeatable)",
+	"число повторных попыток чтения файла при ошибках ввода-вывода":                              "number of read retries on I/O errors",
+	"подробный вывод":                                                                                                "verbose output",
+	"не ждать нажатия Enter перед выходом в Windows":                                                                 "do not wait for Enter before exiting on Windows",
+	"свести записи по значению колонки в одну строку на группу":                                                      "collapse records by column value into one row per group",
+	"колонка, суммируемая внутри группы -group-by (можно указать несколько раз)":                                     "column summed within a -group-by group (repeatable)",
+	"какой из повторяющихся элементов блока брать: first, last или join":                                             "which of repeated block elements to take: first, last or join",
+	"разделитель значений для -on-dup join":                                                                          "value separator for -on-dup join",
+	"номера блоков в каждом файле через запятую, начиная с 1 (по умолчанию все)":                                     "comma-separated block numbers in each file, starting at 1 (default all)",
+	"завершиться с ошибкой, если в записях есть колонки вне заданного порядка полей":                                 "fail if records contain columns outside the configured field order",
+	"записать результат каждого XML файла в отдельный CSV с тем же именем":                                           "write each XML file's records to a separate CSV with the same name",
+	"в режиме -per-file использовать общий набор колонок для всех файлов":                                            "use one column set for all files in -per-file mode",
+	"записать рядом с результатом файл контрольной суммы: sha256 или md5":                                            "write a checksum file next to the output: sha256 or md5",
+	"сортировать записи по колонке, КОЛОНКА[:desc] (можно указать несколько раз)":                                    "sort records by column, COLUMN[:desc] (repeatable)",
+	"колонки результата и их порядок через запятую":                                                                  "comma-separated output columns and their order",
+	"файл со списком колонок результата, по одной в строке":                                                          "file listing output columns, one per line",
+	"транслитерировать кириллицу латиницей во всех колонках (ICAO Doc 9303)":                                         "transliterate Cyrillic to Latin in all columns (ICAO Doc 9303)",
+	"действие при нарушении min=/max= поля: warn или reject":                                                         "action on a min=/max= field violation: warn or reject",
+	"объединить готовые CSV файлы (через запятую) в один вместо обработки XML":                                       "merge existing CSV files (comma-separated) into one instead of processing XML",
+	"записать результат транспонированным: поле в строке, запись в колонке":                                          "write the output transposed: fields as rows, records as columns",
+	"максимальное число записей для -transpose":                                                                      "maximum number of records for -transpose",
+	"добавить колонку %s со временем конвертации (RFC3339)":                                                          "add a %s column with the conversion time (RFC3339)",
+	"часовой пояс для %s: utc или local":                                                                             "time zone for %s: utc or local",
+	"если файл результата существует: overwrite, skip, error или rename (по умолчанию overwrite, с -output — error)": "if the output file exists: overwrite, skip, error or rename (default overwrite, error with -output)",
+	"файл состояния: пропускать XML файлы, не изменившиеся с прошлого запуска":                                       "state file: skip XML files unchanged since the previous run",
+	"добавить колонку %s с XML каждого блока":                                                                        "add a %s column with each block's XML",
+	"проверить уникальность значений колонки во всех записях":                                                        "check that the column's values are unique across all records",
+	"завершиться с ошибкой, если значения -id-column повторяются":                                                    "fail if -id-column values are repeated",
+	"привести значения к форме Unicode: nfc или nfd":                                                                 "normalize values to a Unicode form: nfc or nfd",
+	"наибольшее допустимое число блоков в одном файле (0 — без ограничения)":                                         "maximum number of blocks allowed in one file (0 for no limit)",
+	"что делать при превышении -max-blocks-per-file: warn или error":                                                 "what to do when -max-blocks-per-file is exceeded: warn or error",
+	"записывать результат частями не более чем по N строк (0 — одним файлом)":                                        "write the output in parts of at most N rows (0 for a single file)",
+	"выбрать поля и имена колонок по первому XML файлу в диалоге":                                                    "choose fields and column names interactively from the first XML file",
+	"вывести число различных значений и самые частые значения каждой колонки":                                        "print distinct value counts and the most frequent values of each column",
+	"записать статистику -stats в файл вместо вывода на экран":                                                       "write -stats output to a file instead of the screen",
+	"разделитель входных файлов -merge-csv (auto — определить по первой строке)":                                     "delimiter of -merge-csv input files (auto to detect from the first line)",
+	"при отсутствии записей записать файл только с заголовком":                                                       "write a header-only file when there are no records",
+	"заключать в кавычки все поля результата":                                                                        "quote every output field",
+	"Использование: %s [флаги] [--] [каталог_с_xml] [файл_конфигурации]\n":                                           "Usage: %s [flags] [--] [xml_dir] [config_file]\n",
+	"Аргументы после -- считаются путями, даже если начинаются с дефиса.":                                            "Arguments after -- are treated as paths even if they start with a dash.",
+	"Флаги:": "Flags:",
+	"Неизвестный режим -on-dup:":                                        "Unknown -on-dup mode:",
+	"Неизвестный режим -max-blocks-mode:":                               "Unknown -max-blocks-mode:",
+	"Лимит блоков не может быть отрицательным:":                         "Block limit cannot be negative:",
+	"Неизвестная форма -normalize-unicode:":                             "Unknown -normalize-unicode form:",
+	"Неизвестная кодировка:":                                            "Unknown encoding:",
+	"Неизвестное окончание строк:":                                      "Unknown line ending:",
+	"Неизвестный алгоритм контрольной суммы:":                           "Unknown checksum algorithm:",
+	"Ошибка при чтении списка колонок:":                                 "Error reading the column list:",
+	"Ошибка при выводе конфигурации:":                                   "Error printing the configuration:",
+	"В конфигурации не задан":                                           "Configuration does not set",
+	"В разделе [%s] конфигурации не задан %s\n":                         "Section [%s] of the configuration does not set %s\n",
+	"В разделе %s конфигурации не задан %s\n":                           "Section %s of the configuration does not set %s\n",
+	"Недопустимое значение round=%q для колонки %q":                     "Invalid round=%q for column %q",
+	"Параметр round колонки %q действует только вместе с type=number\n": "Option round of column %q only applies together with type=number\n",
+	"Неизвестный режим -range-mode:":                                    "Unknown -range-mode:",
+	"Флаг -sum требует -group-by":                                       "Flag -sum requires -group-by",
+	"Флаги -per-file и -output несовместимы":                            "Flags -per-file and -output are incompatible",
+	"Неизвестный формат -format:":                                       "Unknown -format:",
+	"Неизвестный режим -if-exists:":                                     "Unknown -if-exists mode:",
+	"Неизвестный часовой пояс -timestamp-zone:":                         "Unknown -timestamp-zone:",
+	"Ошибка при создании каталога результата:":                          "Error creating the output directory:",
+	"Размер части не может быть отрицательным:":                         "Part size cannot be negative:",
+	"Ошибка при поиске XML файлов:":                                     "Error searching for XML files:",
+	"В каталоге %q не найдено файлов по шаблону %q\n":                   "Directory %q has no files matching %q\n",
+	"Ошибка при чтении сведений о файле:":                               "Error reading file information:",
+	"Новых или изменённых файлов нет":                                   "No new or modified files",
+	"Файлов к обработке: %d из %d\n":                                    "Files to process: %d of %d\n",
+	"Число обработчиков должно быть положительным:":                     "Number of workers must be positive:",
+	"Лимит открытых файлов должен быть положительным:":                  "Open file limit must be positive:",
+	"Режим -interactive доступен только при вводе с терминала":          "Mode -interactive requires terminal input",
+	"Таймаут": "Timeout",
+	"Отброшено записей без значения в колонке %q: %d\n":                                  "Records dropped for an empty value in column %q: %d\n",
+	"Нет данных, записывается только заголовок":                                          "No data, writing the header only",
+	"Записей больше %d, %s записан без транспонирования\n":                               "More than %d records, %s written without transposing\n",
+	"Ошибка при записи статистики:":                                                      "Error writing statistics:",
+	"Ошибка при записи описания колонок:":                                                "Error writing the column description:",
+	"Нет данных... завершение программы":                                                 "No data... exiting",
+	"файл %s содержит DTD, обработка запрещена флагом -no-dtd":                           "file %s contains a DTD, processing is disabled by -no-dtd",
+	"ошибка в файле %s: %w":                                                              "error in file %s: %w",
+	"Файл %s содержит включения xi:include (%d), они пропущены; используйте -xinclude\n": "File %s contains xi:include elements (%d), they are skipped; use -xinclude\n",
+	"в файле %s блоков %s больше допустимого: %d (лимит %d)":                             "file %s has more %s blocks than allowed: %d (limit %d)",
+	"В файле %s блоков %s больше допустимого: %d (лимит %d)\n":                           "File %s has more %s blocks than allowed: %d (limit %d)\n",
+	"ошибка при сериализации блока в файле %s: %w":                                       "error serializing a block in file %s: %w",
+	"В файле %s пропущено элементов %s сверх max=%d: %d\n":                               "File %s: skipped %s elements beyond max=%d: %d\n",
+	"недопустимый шаблон тега блока %q: %w":                                              "invalid block tag pattern %q: %w",
+	"недопустимый номер блока: %q":                                                       "invalid block number: %q",
+	"В файле %s нет блока с номером %d (всего блоков: %d)\n":                             "File %s has no block number %d (blocks in total: %d)\n",
+	"формат времени %q даёт недопустимое имя файла: %q":                                  "time layout %q gives an invalid file name: %q",
+	"ошибка при проверке файла результата: %w":                                           "error checking the output file: %w",
+	"Файл %s уже существует, запись пропущена\n":                                         "File %s already exists, writing skipped\n",
+	"файл %s уже существует":                                                             "file %s already exists",
+	"Файл %s уже существует, результат записан в %s\n":                                   "File %s already exists, output written to %s\n",
+	"ошибка при создании файла результата: %w":                                           "error creating the output file: %w",
+	"ошибка при закрытии файла результата: %w":                                           "error closing the output file: %w",
+	"ошибка при сжатии файла результата: %w":                                             "error compressing the output file: %w",
+	"ошибка при записи BOM: %w":                                                          "error writing the BOM: %w",
+	"ошибка при записи преамбулы: %w":                                                    "error writing the preamble: %w",
+	"ошибка при записи заголовков: %w":                                                   "error writing the header: %w",
+	"ошибка при записи строки: %w":                                                       "error writing a row: %w",
+	"ошибка при записи CSV файла: %w":                                                    "error writing the CSV file: %w",
+	"%q (записей: %d)": "%q (records: %d)",
+	"в записях есть колонки вне заданного порядка полей: %s":               "records contain columns outside the configured field order: %s",
+	"Колонка %q пуста в %.0f%% записей, возможно сопоставление устарело\n": "Column %q is empty in %.0f%% of records, the mapping may be outdated\n",
+	"ошибка при записи контрольной суммы: %w":                              "error writing the checksum: %w",
+	"Колонка %q не найдена ни в одной записи\n":                            "Column %q not found in any record\n",
+	"ошибка при чтении CSV файла %s: %w":                                   "error reading CSV file %s: %w",
+	"во входных CSV файлах нет строк данных":                               "the input CSV files have no data rows",
+	"ожидается %sимя=Колонка":                                              "expected %sname=Column",
+	"ожидается %sрегулярное_выражение=Колонка":                             "expected %sregexp=Column",
+	"не задан префикс колонок":                                             "column prefix is not set",
+	"ожидается max=N с положительным N":                                    "expected max=N with a positive N",
+	"ошибка при чтении файла состояния: %w":                                "error reading the state file: %w",
+	"ошибка в файле состояния %s: %w":                                      "error in state file %s: %w",
+	"ошибка при записи файла состояния: %w":                                "error writing the state file: %w",
+	"%s: различных значений %d, пустых %d\n":                               "%s: distinct values %d, empty %d\n",
+	"превышена глубина вложенности xi:include (%d)":                        "xi:include nesting depth exceeded (%d)",
+	"неподдерживаемое включение xi:include href=%q":                        "unsupported xi:include href=%q",
+	"ошибка при чтении включения %s: %w":                                   "error reading include %s: %w",
+	"включение %s содержит DTD":                                            "include %s contains a DTD",
+	"включение %s не содержит корневого элемента":                          "include %s has no root element",
+	"неподдерживаемое значение parse=%q в xi:include":                      "unsupported parse=%q in xi:include",
+	"Файл %s не изменился с прошлого запуска, пропущен\n":                  "File %s unchanged since the previous run, skipped\n",
+	"Повторное чтение файла %s через %v (попытка %d из %d): %v\n":          "Retrying read of file %s in %v (attempt %d of %d): %v\n",
+	"Разделитель файла %s: %q\n":                                           "Delimiter of file %s: %q\n",
+}
+
+func tr(message string) string {
+	if lang == "en" {
+		if translated, ok := englishMessages[message]; ok {
+			return translated
+		}
+	}
+	return message
+}
+
+func langFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "lang" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return lang
+}
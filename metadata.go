@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	piPrefix      = "pi:"
+	commentPrefix = "comment:"
+)
+
+type DocumentField struct {
+	Target  string
+	Pattern *regexp.Regexp
+	Column  string
+}
+
+func parseDocumentField(line string) (DocumentField, error) {
+	if rest, ok := strings.CutPrefix(line, piPrefix); ok {
+		target, column, found := strings.Cut(rest, "=")
+		if !found || strings.TrimSpace(target) == "" || strings.TrimSpace(column) == "" {
+			return DocumentField{}, fmt.Errorf(tr("ожидается %sимя=Колонка"), piPrefix)
+		}
+		return DocumentField{Target: strings.TrimSpace(target), Column: strings.TrimSpace(column)}, nil
+	}
+
+	rest := strings.TrimPrefix(line, commentPrefix)
+	i := strings.LastIndex(rest, "=")
+	if i <= 0 || strings.TrimSpace(rest[i+1:]) == "" {
+		return DocumentField{}, fmt.Errorf(tr("ожидается %sрегулярное_выражение=Колонка"), commentPrefix)
+	}
+	pattern, err := regexp.Compile(strings.TrimSpace(rest[:i]))
+	if err != nil {
+		return DocumentField{}, err
+	}
+	return DocumentField{Pattern: pattern, Column: strings.TrimSpace(rest[i+1:])}, nil
+}
+
+func (f DocumentField) String() string {
+	if f.Pattern != nil {
+		return commentPrefix + f.Pattern.String() + "=" + f.Column
+	}
+	return piPrefix + f.Target + "=" + f.Column
+}
+
+func (f DocumentField) match(token etree.Token) (string, bool) {
+	switch t := token.(type) {
+	case *etree.ProcInst:
+		if f.Pattern == nil && t.Target == f.Target {
+			return strings.TrimSpace(t.Inst), true
+		}
+	case *etree.Comment:
+		if f.Pattern == nil {
+			return "", false
+		}
+		if groups := f.Pattern.FindStringSubmatch(t.Data); groups != nil {
+			if len(groups) > 1 {
+				return strings.TrimSpace(groups[1]), true
+			}
+			return strings.TrimSpace(groups[0]), true
+		}
+	}
+	return "", false
+}
+
+func documentValues(doc *etree.Document, fields []DocumentField) map[string]string {
+	values := make(map[string]string)
+	var walk func(tokens []etree.Token)
+	walk = func(tokens []etree.Token) {
+		for _, token := range tokens {
+			if elem, ok := token.(*etree.Element); ok {
+				walk(elem.Child)
+				continue
+			}
+			for _, field := range fields {
+				if _, found := values[field.Column]; found {
+					continue
+				}
+				if value, ok := field.match(token); ok {
+					values[field.Column] = value
+				}
+			}
+		}
+	}
+	walk(doc.Child)
+	return values
+}
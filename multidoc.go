@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+
+	"github.com/beevik/etree"
+)
+
+var xmlDeclaration = regexp.MustCompile(`<\?xml[ \t\r\n]`)
+
+func splitDocuments(data []byte) [][]byte {
+	var fragments [][]byte
+	start := 0
+	for _, loc := range xmlDeclaration.FindAllIndex(data, -1) {
+		if loc[0] > start {
+			fragments = append(fragments, data[start:loc[0]])
+		}
+		start = loc[0]
+	}
+	fragments = append(fragments, data[start:])
+
+	kept := fragments[:0]
+	for _, fragment := range fragments {
+		fragment = bytes.Trim(fragment, "\uFEFF \t\r\n")
+		if len(fragment) > 0 {
+			kept = append(kept, fragment)
+		}
+	}
+	return kept
+}
+
+func readMultiDocument(doc *etree.Document, filename string, config *Config) error {
+	data, err := readInput(filename, config)
+	if err != nil {
+		return err
+	}
+	for i, fragment := range splitDocuments(data) {
+		part := etree.NewDocument()
+		part.ReadSettings = doc.ReadSettings
+		if err := part.ReadFromBytes(fragment); err != nil {
+			return fmt.Errorf(tr("документ %d: %w"), i+1, err)
+		}
+		for _, root := range part.ChildElements() {
+			doc.AddChild(root)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const nestedSectionPrefix = "nested:"
+
+type NestedDefinition struct {
+	Prefix   string
+	Max      int
+	FieldMap map[string]string
+	Fields   []string
+}
+
+func parseNestedHeader(header string) (*NestedDefinition, error) {
+	prefix, options := parseFieldOptions(strings.TrimPrefix(header, nestedSectionPrefix))
+	if prefix == "" {
+		return nil, errors.New(tr("не задан префикс колонок"))
+	}
+	max, err := strconv.Atoi(options["max"])
+	if err != nil || max < 1 {
+		return nil, errors.New(tr("ожидается max=N с положительным N"))
+	}
+	return &NestedDefinition{Prefix: prefix, Max: max, FieldMap: make(map[string]string)}, nil
+}
+
+func (n *NestedDefinition) header() string {
+	return "[" + nestedSectionPrefix + n.Prefix + ";max=" + strconv.Itoa(n.Max) + "]"
+}
+
+func (n *NestedDefinition) column(index int, field string) string {
+	return n.Prefix + "_" + strconv.Itoa(index) + "_" + field
+}
+
+func (n *NestedDefinition) columns() []string {
+	var columns []string
+	for i := 1; i <= n.Max; i++ {
+		for _, field := range n.Fields {
+			columns = append(columns, n.column(i, field))
+		}
+	}
+	return columns
+}
+
+func (n *NestedDefinition) lines() []string {
+	lines := []string{n.header()}
+	if tag, exists := n.FieldMap[parserOpenBlockTagLiteral]; exists {
+		lines = append(lines, parserOpenBlockTagLiteral+"="+tag)
+	}
+	xmlTags := make([]string, 0, len(n.FieldMap))
+	for xmlTag := range n.FieldMap {
+		if xmlTag != parserOpenBlockTagLiteral {
+			xmlTags = append(xmlTags, xmlTag)
+		}
+	}
+	sort.Strings(xmlTags)
+	for _, field := range n.Fields {
+		for _, xmlTag := range xmlTags {
+			if n.FieldMap[xmlTag] == field {
+				lines = append(lines, xmlTag+"="+field)
+			}
+		}
+	}
+	return lines
+}
+
+func (n *NestedDefinition) extract(block *etree.Element, record Record, config *Config) int {
+	mappings, _ := compileMappings(&Config{FieldMap: n.FieldMap})
+	tags, paths := mappingSources(mappings)
+	items := block.FindElements(blockPath(n.FieldMap[parserOpenBlockTagLiteral]))
+	for i, item := range items {
+		if i >= n.Max {
+			return len(items) - n.Max
+		}
+		elements := gatherElements(item, tags, paths)
+		for _, mapping := range mappings {
+			if value, ok := mapping.resolve(item, elements, config); ok {
+				record[n.column(i+1, mapping.csvField)] = value
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func parquetNode(config *Config, header string) parquet.Node {
+	switch config.FieldOptions[header]["type"] {
+	case "number":
+		return parquet.Optional(parquet.Leaf(parquet.DoubleType))
+	case "int":
+		return parquet.Optional(parquet.Leaf(parquet.Int64Type))
+	case "date":
+		return parquet.Optional(parquet.Date())
+	}
+	return parquet.Optional(parquet.String())
+}
+
+func parquetValue(value string, options FieldOptions) (parquet.Value, bool) {
+	if value == "" {
+		return parquet.NullValue(), true
+	}
+	switch options["type"] {
+	case "number":
+		number, ok := parseNumber(value, options)
+		if !ok {
+			return parquet.NullValue(), false
+		}
+		return parquet.DoubleValue(number), true
+	case "int":
+		normalized, ok := normalizeNumber(value, options)
+		if !ok {
+			return parquet.NullValue(), false
+		}
+		number, err := strconv.ParseInt(normalized, 10, 64)
+		if err != nil {
+			return parquet.NullValue(), false
+		}
+		return parquet.Int64Value(number), true
+	case "date":
+		t, ok := parseDate(value)
+		if !ok {
+			return parquet.NullValue(), false
+		}
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return parquet.Int32Value(int32(day.Unix() / 86400)), true
+	}
+	return parquet.ByteArrayValue([]byte(value)), true
+}
+
+func writeParquet(filename string, records []Record, config *Config) error {
+	return createOutput(filename, config, func(out io.Writer) error {
+		group := make(parquet.Group)
+		for _, header := range getHeaders(records, config) {
+			group[header] = parquetNode(config, header)
+		}
+		schema := parquet.NewSchema("records", group)
+		fields := schema.Fields()
+
+		options := []parquet.WriterOption{schema, parquet.Compression(&parquet.Snappy)}
+		if m := config.Metadata; m != nil {
+			options = append(options,
+				parquet.KeyValueMetadata("xml_to_csv.version", m.Version),
+				parquet.KeyValueMetadata("xml_to_csv.created", m.Created),
+				parquet.KeyValueMetadata("xml_to_csv.input", m.Input),
+				parquet.KeyValueMetadata("xml_to_csv.files", strconv.Itoa(m.Files)),
+				parquet.KeyValueMetadata("xml_to_csv.config", strings.Join(m.Config, "\n")),
+			)
+		}
+		writer := parquet.NewWriter(out, options...)
+		invalid := make(map[string]int)
+		for _, record := range records {
+			row := make(parquet.Row, len(fields))
+			for i, field := range fields {
+				value, ok := parquetValue(record[field.Name()], config.FieldOptions[field.Name()])
+				if !ok {
+					invalid[field.Name()]++
+				}
+				definition := 1
+				if value.IsNull() {
+					definition = 0
+				}
+				row[i] = value.Level(0, definition, i)
+			}
+			if _, err := writer.WriteRows([]parquet.Row{row}); err != nil {
+				return fmt.Errorf(tr("ошибка при записи строки: %w"), err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return fmt.Errorf(tr("ошибка при записи Parquet файла: %w"), err)
+		}
+		for _, field := range fields {
+			if invalid[field.Name()] > 0 {
+				warnf("invalid-type", filename, field.Name(), "В колонке %q записано пустыми значений неверного типа: %d\n", field.Name(), invalid[field.Name()])
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestRunParquetReadBack(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2, 3))
+	config := writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер;type=number\nGoodsDescription=Название\n")
+	output := filepath.Join(t.TempDir(), "result.parquet")
+
+	if code, out := runArgs(t, "-no-defaults", "-format", "parquet", "-output", output, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+
+	file, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := parquet.OpenFile(file, info.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := pf.NumRows(); n != 3 {
+		t.Errorf("строк %d; ожидалось 3", n)
+	}
+	fields := pf.Schema().Fields()
+	if len(fields) != 2 {
+		t.Fatalf("колонок %d; ожидалось 2", len(fields))
+	}
+	if fields[0].Name() != "Название" || fields[1].Name() != "Номер" {
+		t.Errorf("колонки %q, %q", fields[0].Name(), fields[1].Name())
+	}
+	if kind := fields[1].Type().Kind(); kind != parquet.Double {
+		t.Errorf("тип колонки Номер %v; ожидался DOUBLE", kind)
+	}
+}
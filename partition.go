@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	partitionKey     = "\x00partition"
+	partitionNameMax = 64
+)
+
+func partitionValue(block *etree.Element, name string) string {
+	for elem := block; elem != nil; elem = elem.Parent() {
+		if attr := elem.SelectAttr(name); attr != nil {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+func takePartitions(results [][]Record) [][]string {
+	partitions := make([][]string, len(results))
+	for i, records := range results {
+		partitions[i] = make([]string, len(records))
+		for j, record := range records {
+			partitions[i][j] = record[partitionKey]
+			delete(record, partitionKey)
+		}
+	}
+	return partitions
+}
+
+func partitionFileName(value string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, value)
+	if runes := []rune(name); len(runes) > partitionNameMax {
+		name = string(runes[:partitionNameMax])
+	}
+	name = strings.TrimRight(strings.TrimSpace(name), ".")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
+
+func partitionSets(set outputSet, values []string, owners map[string]string) ([]outputSet, error) {
+	var sets []outputSet
+	index := make(map[string]int)
+	for i, record := range set.records {
+		filename := set.filename
+		if value := values[i]; value != "" {
+			name := partitionFileName(value)
+			if owner, taken := owners[name]; taken && owner != value {
+				return nil, fmt.Errorf(tr("значения атрибута %q и %q дают одно имя файла %q"), owner, value, name)
+			}
+			owners[name] = value
+			filename = insertSuffix(set.filename, "_"+name)
+		}
+		j, found := index[filename]
+		if !found {
+			j = len(sets)
+			index[filename] = j
+			sets = append(sets, outputSet{filename: filename})
+		}
+		sets[j].records = append(sets[j].records, record)
+	}
+	if len(sets) == 0 {
+		return []outputSet{set}, nil
+	}
+	return sets, nil
+}
+
+func perFileSets(files []string, results [][]Record, partitions [][]string, outDir string, config *Config) ([]outputSet, error) {
+	var sets []outputSet
+	owners := make(map[string]string)
+	sources := make(map[string]string)
+	for i, file := range files {
+		name := perFileName(file, outDir, "."+config.Format, config.Gzip)
+		if other, taken := sources[strings.ToLower(name)]; taken {
+			return nil, fmt.Errorf(tr("Файлы %s и %s дают один файл результата %s с -per-file"), other, file, name)
+		}
+		sources[strings.ToLower(name)] = file
+		set := outputSet{filename: name, records: results[i]}
+		if partitions == nil {
+			sets = append(sets, set)
+			continue
+		}
+		parts, err := partitionSets(set, partitions[i], owners)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, parts...)
+	}
+	return sets, nil
+}
+
+func combinedSets(filename string, results [][]Record, partitions [][]string) ([]outputSet, error) {
+	set := outputSet{filename: filename}
+	var values []string
+	for i, recs := range results {
+		set.records = append(set.records, recs...)
+		if partitions != nil {
+			values = append(values, partitions[i]...)
+		}
+	}
+	if partitions == nil {
+		return []outputSet{set}, nil
+	}
+	return partitionSets(set, values, make(map[string]string))
+}
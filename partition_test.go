@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPartitionFileName(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"A", "A"},
+		{"a/b", "a_b"},
+		{"a:b", "a_b"},
+		{" партия 1. ", "партия 1"},
+		{"...", "_"},
+		{strings.Repeat("я", 70), strings.Repeat("я", partitionNameMax)},
+	}
+	for _, tt := range tests {
+		if got := partitionFileName(tt.value); got != tt.want {
+			t.Errorf("partitionFileName(%q) = %q; ожидалось %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRunPartitionByAttr(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", `<ESADout_CU>
+<Consignment id="A"><ESADout_CUGoods><GoodsNumeric>1</GoodsNumeric></ESADout_CUGoods></Consignment>
+<Consignment id="B"><ESADout_CUGoods><GoodsNumeric>2</GoodsNumeric></ESADout_CUGoods>
+<ESADout_CUGoods id="A"><GoodsNumeric>3</GoodsNumeric></ESADout_CUGoods></Consignment>
+<ESADout_CUGoods><GoodsNumeric>4</GoodsNumeric></ESADout_CUGoods>
+<Consignment id="A"><ESADout_CUGoods><GoodsNumeric>5</GoodsNumeric></ESADout_CUGoods></Consignment>
+</ESADout_CU>`)
+	config := writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\n")
+	outDir := t.TempDir()
+	output := filepath.Join(outDir, "result.csv")
+	if code, out := runArgs(t, "-no-defaults", "-partition-by-attr", "id", "-output", output, dataDir, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+
+	want := map[string][]string{
+		"result_A.csv": {"Номер", "1", "3", "5"},
+		"result_B.csv": {"Номер", "2"},
+		"result.csv":   {"Номер", "4"},
+	}
+	entries, err := os.ReadDir(outDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != len(want) {
+		t.Errorf("файлов %d; ожидалось %d", len(entries), len(want))
+	}
+	for name, lines := range want {
+		if got := readLines(t, filepath.Join(outDir, name)); strings.Join(got, "|") != strings.Join(lines, "|") {
+			t.Errorf("%s: %q; ожидалось %q", name, got, lines)
+		}
+	}
+}
+
+func TestRunPartitionByAttrCollision(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", `<ESADout_CU><ESADout_CUGoods id="a/b"><GoodsNumeric>1</GoodsNumeric></ESADout_CUGoods><ESADout_CUGoods id="a:b"><GoodsNumeric>2</GoodsNumeric></ESADout_CUGoods></ESADout_CU>`)
+	config := writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\n")
+	outDir := t.TempDir()
+	if code, _ := runArgs(t, "-no-defaults", "-partition-by-attr", "id", "-output", filepath.Join(outDir, "result.csv"), dataDir, config); code != exitError {
+		t.Errorf("код %d; ожидалось %d", code, exitError)
+	}
+	if entries, _ := os.ReadDir(outDir); len(entries) != 0 {
+		t.Errorf("при совпадении имён записаны файлы: %v", entries)
+	}
+}
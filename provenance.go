@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const metadataPrefix = "# "
+
+type RunMetadata struct {
+	Tool    string   `json:"tool"`
+	Version string   `json:"version"`
+	Created string   `json:"created"`
+	Input   string   `json:"input"`
+	Files   int      `json:"files"`
+	Config  []string `json:"config"`
+}
+
+func toolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	version := "devel"
+	modified := false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			version += "-" + setting.Value[:min(12, len(setting.Value))]
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if modified {
+		version += "-dirty"
+	}
+	return version
+}
+
+func newRunMetadata(input string, files int, config *Config, now time.Time) (*RunMetadata, error) {
+	var buf bytes.Buffer
+	if err := writeConfig(&buf, config); err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return &RunMetadata{
+		Tool:    "xml_to_csv",
+		Version: toolVersion(),
+		Created: now.Format(time.RFC3339),
+		Input:   input,
+		Files:   files,
+		Config:  lines,
+	}, nil
+}
+
+func (m *RunMetadata) lines() []string {
+	lines := []string{
+		metadataPrefix + m.Tool + " " + m.Version,
+		metadataPrefix + "created: " + m.Created,
+		metadataPrefix + "input: " + m.Input,
+		metadataPrefix + "files: " + strconv.Itoa(m.Files),
+	}
+	for _, line := range m.Config {
+		lines = append(lines, metadataPrefix+"config: "+line)
+	}
+	return lines
+}
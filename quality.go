@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strconv"
+)
+
+var qualityColumns = []string{"Колонка", "Строк", "Заполнено", "Пустых", "Различных", "Минимум", "Максимум"}
+
+func buildQuality(records []Record, config *Config) []Record {
+	var rows []Record
+	for _, header := range getHeaders(records, config) {
+		distinct := make(map[string]bool)
+		filled := 0
+		allNumeric := true
+		var minimum, maximum float64
+		var minText, maxText string
+		for _, record := range records {
+			value := record[header]
+			if value == "" {
+				continue
+			}
+			filled++
+			distinct[value] = true
+			normalized, ok := normalizeNumber(value, config.FieldOptions[header])
+			number, err := strconv.ParseFloat(normalized, 64)
+			if !ok || err != nil {
+				allNumeric = false
+				continue
+			}
+			if minText == "" || number < minimum {
+				minimum, minText = number, normalized
+			}
+			if maxText == "" || number > maximum {
+				maximum, maxText = number, normalized
+			}
+		}
+		row := Record{
+			qualityColumns[0]: header,
+			qualityColumns[1]: strconv.Itoa(len(records)),
+			qualityColumns[2]: strconv.Itoa(filled),
+			qualityColumns[3]: strconv.Itoa(len(records) - filled),
+			qualityColumns[4]: strconv.Itoa(len(distinct)),
+		}
+		if allNumeric || numericField(config.FieldOptions[header]) {
+			row[qualityColumns[5]] = minText
+			row[qualityColumns[6]] = maxText
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func writeQuality(filename string, records []Record, config *Config) error {
+	return writeCSV(filename, buildQuality(records, config), reportConfig(config, qualityColumns))
+}
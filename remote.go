@@ -0,0 +1,117 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout = 30 * time.Second
+	httpPasswordEnv    = "XML_TO_CSV_HTTP_PASSWORD"
+)
+
+type HTTPSettings struct {
+	Timeout  time.Duration
+	Header   http.Header
+	User     string
+	Password string
+}
+
+func isURL(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func parseHTTPHeaders(values []string) (http.Header, error) {
+	header := make(http.Header)
+	for _, value := range values {
+		name, content, ok := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf(tr("ожидается ИМЯ: ЗНАЧЕНИЕ, получено %q"), value)
+		}
+		header.Add(name, strings.TrimSpace(content))
+	}
+	return header, nil
+}
+
+func parseHTTPUser(value string) (string, string, error) {
+	user, password, ok := strings.Cut(value, ":")
+	if !ok {
+		password, ok = os.LookupEnv(httpPasswordEnv)
+	}
+	if user == "" || !ok {
+		return "", "", fmt.Errorf(tr("ожидается ПОЛЬЗОВАТЕЛЬ:ПАРОЛЬ или пароль в переменной %s"), httpPasswordEnv)
+	}
+	return user, password, nil
+}
+
+func newHTTPSettings(opts *runOptions) (*HTTPSettings, error) {
+	settings := &HTTPSettings{Timeout: opts.httpTimeout}
+	var err error
+	if settings.Header, err = parseHTTPHeaders(opts.httpHeaders); err != nil {
+		return nil, fmt.Errorf("%s %w", tr("Ошибка в -http-header:"), err)
+	}
+	if opts.httpUser != "" {
+		if settings.User, settings.Password, err = parseHTTPUser(opts.httpUser); err != nil {
+			return nil, fmt.Errorf("%s %w", tr("Ошибка в -http-user:"), err)
+		}
+	}
+	return settings, nil
+}
+
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New(tr("слишком много перенаправлений"))
+	}
+	if via[0].URL.Scheme == "https" && req.URL.Scheme != "https" {
+		return fmt.Errorf(tr("перенаправление с https на %s запрещено"), req.URL.Scheme)
+	}
+	return nil
+}
+
+func fetchURL(address string, settings *HTTPSettings) ([]byte, error) {
+	timeout := defaultHTTPTimeout
+	if settings != nil {
+		timeout = settings.Timeout
+	}
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		for name, values := range settings.Header {
+			req.Header[name] = values
+		}
+		if settings.User != "" {
+			req.SetBasicAuth(settings.User, settings.Password)
+		}
+	}
+	client := &http.Client{Timeout: timeout, CheckRedirect: checkRedirect}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf(tr("сервер ответил %s"), resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &url.Error{Op: "Get", URL: address, Err: err}
+	}
+	return data, nil
+}
+
+func readInput(filename string, config *Config) ([]byte, error) {
+	if isURL(filename) {
+		return fetchURL(filename, config.HTTP)
+	}
+	return os.ReadFile(filename)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moved":
+			http.Redirect(w, r, "/feed.xml", http.StatusFound)
+		case "/feed.xml":
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "user" || password != "secret" || r.Header.Get("X-Token") != "abc" {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(goodsDocument(1)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	settings := &HTTPSettings{Timeout: time.Second, Header: http.Header{"X-Token": {"abc"}}, User: "user", Password: "secret"}
+	data, err := fetchURL(server.URL+"/moved", settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != goodsDocument(1) {
+		t.Errorf("получено %q", data)
+	}
+
+	if _, err := fetchURL(server.URL+"/feed.xml", &HTTPSettings{Timeout: time.Second}); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("без входа: %v; ожидалась ошибка 403", err)
+	}
+	if _, err := fetchURL(server.URL+"/missing.xml", settings); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("нет файла: %v; ожидалась ошибка 404", err)
+	}
+}
+
+func TestParseHTTPUser(t *testing.T) {
+	if user, password, err := parseHTTPUser("user:a:b"); err != nil || user != "user" || password != "a:b" {
+		t.Errorf("parseHTTPUser(user:a:b) = %q, %q, %v", user, password, err)
+	}
+	t.Setenv(httpPasswordEnv, "secret")
+	if user, password, err := parseHTTPUser("user"); err != nil || user != "user" || password != "secret" {
+		t.Errorf("parseHTTPUser(user) = %q, %q, %v", user, password, err)
+	}
+	if _, _, err := parseHTTPUser(":secret"); err == nil {
+		t.Error("parseHTTPUser(:secret) без ошибки")
+	}
+}
+
+func TestRunURLInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/feed.xml" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(goodsDocument(1, 2)))
+	}))
+	defer server.Close()
+
+	config := writeTestFile(t, t.TempDir(), "cfg", "parser_open_block_tag=ESADout_CUGoods\nGoodsNumeric=Номер\n")
+	output := filepath.Join(t.TempDir(), "result.csv")
+	address := server.URL + "/feed.xml"
+	if code, out := runArgs(t, "-no-defaults", "-with-source", "-output", output, "--", address, config); code != exitOK {
+		t.Fatalf("код %d\n%s", code, out)
+	}
+	lines := readLines(t, output)
+	if len(lines) != 3 || lines[1] != "1;"+address || lines[2] != "2;"+address {
+		t.Errorf("результат %q", lines)
+	}
+
+	code, _ := runArgs(t, "-no-defaults", "-output", output, "--", server.URL+"/missing.xml", config)
+	if code == exitOK {
+		t.Error("ответ 404 не считается ошибкой")
+	}
+	if code, _ := runArgs(t, "-no-defaults", "-xinclude", "-output", output, "--", address, config); code != exitError {
+		t.Errorf("-xinclude с адресом: код %d; ожидалось %d", code, exitError)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+const rowChunkSize = 256
+
+func encodeRows(records []Record, headers []string, config *Config) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := newRowEncoder(&buf, config)
+	row := make([]string, len(headers))
+	for _, record := range records {
+		for i, header := range headers {
+			row[i] = csvCell(config, header, record[header])
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+func writeRowsParallel(out io.Writer, records []Record, headers []string, config *Config) error {
+	batch := rowChunkSize * config.RowWorkers
+	chunks := make([][]byte, config.RowWorkers)
+	errs := make([]error, config.RowWorkers)
+	for start := 0; start < len(records); start += batch {
+		end := min(start+batch, len(records))
+		var wg sync.WaitGroup
+		for i := range chunks {
+			from := start + i*rowChunkSize
+			chunks[i], errs[i] = nil, nil
+			if from >= end {
+				continue
+			}
+			to := min(from+rowChunkSize, end)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				chunks[i], errs[i] = encodeRows(records[from:to], headers, config)
+			}()
+		}
+		wg.Wait()
+
+		for i, chunk := range chunks {
+			if errs[i] != nil {
+				return fmt.Errorf(tr("ошибка при записи строки: %w"), errs[i])
+			}
+			if _, err := out.Write(chunk); err != nil {
+				return fmt.Errorf(tr("ошибка при записи CSV файла: %w"), err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeGoodsFiles(tb testing.TB, dir string, files, blocks int) {
+	tb.Helper()
+	for i := 0; i < files; i++ {
+		numbers := make([]int, blocks)
+		for j := range numbers {
+			numbers[j] = i*blocks + j + 1
+		}
+		name := filepath.Join(dir, fmt.Sprintf("d%02d.xml", i))
+		if err := os.WriteFile(name, []byte(goodsDocument(numbers...)), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestRunRowWorkersKeepsOrder(t *testing.T) {
+	dataDir := t.TempDir()
+	writeGoodsFiles(t, dataDir, 3, 1000)
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+
+	single := filepath.Join(outDir, "single.csv")
+	if code, out := runArgs(t, "-output", single, dataDir, config); code != exitOK {
+		t.Fatalf("-row-workers 1: код %d\n%s", code, out)
+	}
+	parallel := filepath.Join(outDir, "parallel.csv")
+	if code, out := runArgs(t, "-row-workers", "4", "-output", parallel, dataDir, config); code != exitOK {
+		t.Fatalf("-row-workers 4: код %d\n%s", code, out)
+	}
+
+	want := readLines(t, single)
+	got := readLines(t, parallel)
+	if len(want) != 3001 {
+		t.Fatalf("строк %d; ожидалось 3001", len(want))
+	}
+	if len(got) != len(want) {
+		t.Fatalf("-row-workers 4: строк %d; ожидалось %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("строка %d: %q; ожидалось %q", i+1, got[i], want[i])
+		}
+	}
+}
+
+func BenchmarkRowWorkers(b *testing.B) {
+	dataDir := b.TempDir()
+	writeGoodsFiles(b, dataDir, 10, 1000)
+	config := filepath.Join(dataDir, "missing.cfg")
+	output := filepath.Join(b.TempDir(), "result.csv")
+
+	for _, workers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if code := benchRun(b, "-row-workers", fmt.Sprint(workers), "-if-exists", "overwrite", "-output", output, dataDir, config); code != exitOK {
+					b.Fatalf("код %d", code)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+)
+
+const schemaSampleSize = 1000
+
+type ColumnSchema struct {
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	NonEmpty float64 `json:"non_empty"`
+	Nullable bool    `json:"nullable"`
+}
+
+func buildSchema(records []Record, config *Config) []ColumnSchema {
+	var columns []ColumnSchema
+	for _, header := range getHeaders(records, config) {
+		nonEmpty := 0
+		var sample []string
+		for _, record := range records {
+			if value := record[header]; value != "" {
+				nonEmpty++
+				if len(sample) < schemaSampleSize {
+					sample = append(sample, value)
+				}
+			}
+		}
+		column := ColumnSchema{
+			Name:     header,
+			Type:     inferType(sample, config.FieldOptions[header]),
+			Nullable: nonEmpty < len(records),
+		}
+		if len(records) > 0 {
+			column.NonEmpty = float64(nonEmpty) / float64(len(records))
+		}
+		columns = append(columns, column)
+	}
+	return columns
+}
+
+func inferType(sample []string, options FieldOptions) string {
+	if len(sample) == 0 {
+		return "text"
+	}
+	numbers, dates := true, true
+	for _, value := range sample {
+		if _, ok := normalizeNumber(value, options); !ok {
+			numbers = false
+		}
+		if _, ok := parseDate(value); !ok {
+			dates = false
+		}
+	}
+	switch {
+	case numbers:
+		return "number"
+	case dates:
+		return "date"
+	default:
+		return "text"
+	}
+}
+
+func writeSchema(filename string, records []Record, config *Config) error {
+	data, err := json.MarshalIndent(struct {
+		Columns []ColumnSchema `json:"columns"`
+	}{buildSchema(records, config)}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0o644)
+}
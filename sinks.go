@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type outputSink struct {
+	format   string
+	filename string
+}
+
+func parseSink(spec string) (outputSink, error) {
+	format, filename, ok := strings.Cut(spec, "=")
+	format = strings.TrimSpace(format)
+	if !ok || strings.TrimSpace(filename) == "" {
+		return outputSink{}, fmt.Errorf(tr("ожидается ФОРМАТ=ФАЙЛ, получено %q"), spec)
+	}
+	switch format {
+	case formatCSV, formatXML, formatJSON, formatNDJSON, formatParquet:
+	default:
+		return outputSink{}, fmt.Errorf(tr("неизвестный формат %q в %q"), format, spec)
+	}
+	if format == formatParquet && strings.HasSuffix(strings.ToLower(filename), ".gz") {
+		return outputSink{}, fmt.Errorf(tr("формат parquet несовместим со сжатием: %q"), spec)
+	}
+	return outputSink{format: format, filename: filename}, nil
+}
+
+func parseSinks(specs []string) ([]outputSink, error) {
+	var sinks []outputSink
+	for _, spec := range specs {
+		sink, err := parseSink(spec)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func sinkConfig(sink outputSink, config *Config) *Config {
+	sinkConfig := *config
+	sinkConfig.Format = sink.format
+	sinkConfig.Gzip = strings.HasSuffix(strings.ToLower(sink.filename), ".gz")
+	sinkConfig.Zip = nil
+	switch sink.format {
+	case formatJSON, formatNDJSON, formatParquet:
+		sinkConfig.Encoding = encodingUTF8
+	}
+	if sink.format == formatCSV && config.Format != formatCSV && config.Metadata != nil {
+		sinkConfig.Preamble = append(slices.Clone(config.Preamble), config.Metadata.lines()...)
+	}
+	return &sinkConfig
+}
+
+func writeSinks(sinks []outputSink, records []Record, config *Config) ([]string, error) {
+	var written []string
+	for _, sink := range sinks {
+		if err := writeOutput(sink.filename, records, sinkConfig(sink, config)); err != nil {
+			return written, err
+		}
+		written = append(written, sink.filename)
+	}
+	return written, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type sortKey struct {
+	column     string
+	descending bool
+}
+
+func parseSortKeys(values []string) []sortKey {
+	var keys []sortKey
+	for _, value := range values {
+		column, direction, found := strings.Cut(value, ":")
+		keys = append(keys, sortKey{
+			column:     column,
+			descending: found && strings.EqualFold(direction, "desc"),
+		})
+	}
+	return keys
+}
+
+func sortRecords(records []Record, keys []sortKey, config *Config) {
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, key := range keys {
+			c, ordered := compareValues(records[i][key.column], records[j][key.column], config.FieldOptions[key.column])
+			if c == 0 {
+				continue
+			}
+			if key.descending && ordered {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+func compareValues(a, b string, options FieldOptions) (int, bool) {
+	var x, y float64
+	var okX, okY bool
+	if numericField(options) {
+		x, okX = parseNumber(a, options)
+		y, okY = parseNumber(b, options)
+	} else {
+		var errX, errY error
+		x, errX = strconv.ParseFloat(a, 64)
+		y, errY = strconv.ParseFloat(b, 64)
+		okX, okY = errX == nil, errY == nil
+	}
+
+	switch {
+	case okX && okY:
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+		return 0, true
+	case okX:
+		return -1, false
+	case okY:
+		return 1, false
+	}
+	return strings.Compare(a, b), true
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+type recordSpool struct {
+	threshold int
+	records   []Record
+	keys      Record
+	file      *os.File
+	buffer    *bufio.Writer
+	encoder   *gob.Encoder
+	spilled   int
+}
+
+func newRecordSpool(threshold int) *recordSpool {
+	return &recordSpool{threshold: threshold, keys: make(Record)}
+}
+
+func (s *recordSpool) add(records []Record) error {
+	for _, record := range records {
+		for key := range record {
+			s.keys[key] = ""
+		}
+		if len(s.records) < s.threshold {
+			s.records = append(s.records, record)
+			continue
+		}
+		if s.file == nil {
+			file, err := os.CreateTemp("", "xml_to_csv_spill_*")
+			if err != nil {
+				return fmt.Errorf(tr("ошибка при создании временного файла: %w"), err)
+			}
+			verbosef("Записи сверх %d сохраняются во временный файл %s\n", s.threshold, file.Name())
+			s.file = file
+			s.buffer = bufio.NewWriter(file)
+			s.encoder = gob.NewEncoder(s.buffer)
+		}
+		if err := s.encoder.Encode(record); err != nil {
+			return fmt.Errorf(tr("ошибка при записи во временный файл: %w"), err)
+		}
+		s.spilled++
+	}
+	return nil
+}
+
+func (s *recordSpool) count() int {
+	return len(s.records) + s.spilled
+}
+
+func (s *recordSpool) each(fn func(Record) error) error {
+	for _, record := range s.records {
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+	if s.file == nil {
+		return nil
+	}
+	if err := s.buffer.Flush(); err != nil {
+		return fmt.Errorf(tr("ошибка при записи во временный файл: %w"), err)
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf(tr("ошибка при чтении временного файла: %w"), err)
+	}
+	decoder := gob.NewDecoder(bufio.NewReader(s.file))
+	for {
+		var record Record
+		if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf(tr("ошибка при чтении временного файла: %w"), err)
+		}
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *recordSpool) close() error {
+	if s.file == nil {
+		return nil
+	}
+	var errs []error
+	if err := s.file.Close(); err != nil {
+		errs = append(errs, fmt.Errorf(tr("ошибка при закрытии временного файла %s: %w"), s.file.Name(), err))
+	}
+	if err := os.Remove(s.file.Name()); err != nil {
+		errs = append(errs, fmt.Errorf(tr("ошибка при удалении временного файла: %w"), err))
+	}
+	return errors.Join(errs...)
+}
+
+func writeSpilled(ctx context.Context, files []string, filename string, config *Config, failFast bool, threshold int) (RunSummary, error) {
+	var summary RunSummary
+	spool := newRecordSpool(threshold)
+	defer func() {
+		if err := spool.close(); err != nil {
+			warnf("spill-cleanup", "", "", "%v\n", err)
+		}
+	}()
+	failed := 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		recs, err := parseXML(file, config)
+		var mismatch *rootMismatchError
+		if errors.As(err, &mismatch) {
+			warnf("unexpected-root", file, "", "%v\n", err)
+			statusFile(file, statusSkipped, 0)
+			continue
+		}
+		if err != nil {
+			statusFile(file, statusError, 0)
+			if failFast {
+				return summary, err
+			}
+			warnf("file-error", file, "", "%v\n", err)
+			failed++
+			continue
+		}
+		statusFile(file, statusOK, len(recs))
+		recs, _ = checkRanges(recs, config, rangeModeWarn, nil)
+		if err := spool.add(recs); err != nil {
+			return summary, err
+		}
+	}
+	if spool.spilled > 0 {
+		fmt.Printf(tr("Записей во временном файле: %d из %d\n"), spool.spilled, spool.count())
+	}
+	summary.Blocks, _ = blockTotals()
+	if err := writeStreamed(filename, spool.keys, spool.count(), config, spool.each); err != nil {
+		return summary, err
+	}
+	return streamedSummary(summary, filename, spool.count(), failed, config), nil
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSpillThresholdMatchesInMemory(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2, 3))
+	writeTestFile(t, dataDir, "b.xml", goodsDocument(4, 5))
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+	spillDir := t.TempDir()
+	t.Setenv("TMPDIR", spillDir)
+
+	memory := filepath.Join(outDir, "memory.csv")
+	if code, out := runArgs(t, "-output", memory, dataDir, config); code != exitOK {
+		t.Fatalf("обычный запуск: код %d\n%s", code, out)
+	}
+	spilled := filepath.Join(outDir, "spilled.csv")
+	code, out := runArgs(t, "-spill-threshold", "2", "-output", spilled, dataDir, config)
+	if code != exitOK {
+		t.Fatalf("-spill-threshold: код %d\n%s", code, out)
+	}
+	if !strings.Contains(out, "Записей во временном файле: 3 из 5") {
+		t.Errorf("нет сообщения о временном файле:\n%s", out)
+	}
+
+	want, err := os.ReadFile(memory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(spilled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("-spill-threshold:\n%s\nобычный запуск:\n%s", got, want)
+	}
+	left, err := filepath.Glob(filepath.Join(spillDir, "xml_to_csv_spill_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(left) > 0 {
+		t.Errorf("временные файлы не удалены: %v", left)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type FileState struct {
+	ModTime time.Time `json:"mod_time"`
+	Size    int64     `json:"size"`
+}
+
+type RunState struct {
+	Files map[string]FileState `json:"files"`
+}
+
+func loadState(filename string) (*RunState, error) {
+	state := &RunState{Files: make(map[string]FileState)}
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf(tr("ошибка при чтении файла состояния: %w"), err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf(tr("ошибка в файле состояния %s: %w"), filename, err)
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]FileState)
+	}
+	return state, nil
+}
+
+func fileState(filename string) (string, FileState, error) {
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return "", FileState{}, err
+	}
+	info, err := os.Stat(filename)
+	if err != nil {
+		return "", FileState{}, err
+	}
+	return path, FileState{ModTime: info.ModTime().UTC(), Size: info.Size()}, nil
+}
+
+func changedFiles(files []string, state *RunState) ([]string, map[string]FileState, error) {
+	fileStates := make(map[string]FileState)
+	var changed []string
+	for _, file := range files {
+		path, current, err := fileState(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		if previous, ok := state.Files[path]; ok && previous.ModTime.Equal(current.ModTime) && previous.Size == current.Size {
+			verbosef("Файл %s не изменился с прошлого запуска, пропущен\n", file)
+			continue
+		}
+		fileStates[file] = current
+		changed = append(changed, file)
+	}
+	return changed, fileStates, nil
+}
+
+func (s *RunState) record(file string, current FileState) error {
+	path, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	s.Files[path] = current
+	return nil
+}
+
+func saveState(filename string, state *RunState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при записи файла состояния: %w"), err)
+	}
+	defer func() {
+		if err := os.Remove(tmp.Name()); err != nil && !os.IsNotExist(err) {
+			fmt.Printf(tr("Не удалось удалить временный файл состояния %s: %v\n"), tmp.Name(), err)
+		}
+	}()
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf(tr("ошибка при записи файла состояния: %w"), errors.Join(err, tmp.Close()))
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf(tr("ошибка при записи файла состояния: %w"), err)
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return fmt.Errorf(tr("ошибка при записи файла состояния: %w"), err)
+	}
+	return nil
+}
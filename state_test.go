@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunStateSkipsUnchangedFiles(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	config := filepath.Join(dataDir, "missing.cfg")
+	stateFile := filepath.Join(t.TempDir(), "state.json")
+	outDir := t.TempDir()
+
+	first := filepath.Join(outDir, "first.csv")
+	if code, out := runArgs(t, "-state", stateFile, "-output", first, dataDir, config); code != exitOK {
+		t.Fatalf("первый запуск: код %d\n%s", code, out)
+	}
+	if lines := readLines(t, first); len(lines) != 3 {
+		t.Errorf("первый запуск: строк %d; ожидалось 3", len(lines))
+	}
+
+	writeTestFile(t, dataDir, "b.xml", goodsDocument(3))
+	second := filepath.Join(outDir, "second.csv")
+	if code, out := runArgs(t, "-state", stateFile, "-output", second, dataDir, config); code != exitOK {
+		t.Fatalf("второй запуск: код %d\n%s", code, out)
+	}
+	lines := readLines(t, second)
+	if len(lines) != 2 || lines[1][:1] != "3" {
+		t.Errorf("второй запуск: строки %q; ожидалась только запись из b.xml", lines)
+	}
+
+	state, err := loadState(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Files) != 2 {
+		t.Errorf("в состоянии %d файлов; ожидалось 2", len(state.Files))
+	}
+	if leftovers, _ := filepath.Glob(stateFile + ".*.tmp"); len(leftovers) != 0 {
+		t.Errorf("остались временные файлы: %v", leftovers)
+	}
+
+	third := filepath.Join(outDir, "third.csv")
+	if code, out := runArgs(t, "-state", stateFile, "-output", third, dataDir, config); code != exitOK {
+		t.Fatalf("третий запуск: код %d\n%s", code, out)
+	}
+	if _, err := os.Stat(third); !os.IsNotExist(err) {
+		t.Errorf("без изменений записан результат: %v", err)
+	}
+}
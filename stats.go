@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const statsTopValues = 5
+
+type valueCount struct {
+	value string
+	count int
+}
+
+func writeStats(w io.Writer, records []Record, config *Config) error {
+	for _, header := range getHeaders(records, config) {
+		counts := make(map[string]int)
+		empty := 0
+		for _, record := range records {
+			if value := record[header]; value != "" {
+				counts[value]++
+			} else {
+				empty++
+			}
+		}
+
+		top := make([]valueCount, 0, len(counts))
+		for value, count := range counts {
+			top = append(top, valueCount{value, count})
+		}
+		sort.Slice(top, func(i, j int) bool {
+			if top[i].count != top[j].count {
+				return top[i].count > top[j].count
+			}
+			return top[i].value < top[j].value
+		})
+		if len(top) > statsTopValues {
+			top = top[:statsTopValues]
+		}
+
+		if _, err := fmt.Fprintf(w, tr("%s: различных значений %d, пустых %d\n"), header, len(counts), empty); err != nil {
+			return err
+		}
+		for _, item := range top {
+			if _, err := fmt.Fprintf(w, "  %q: %d\n", item.value, item.count); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func printStats(filename string, records []Record, config *Config) error {
+	if filename == "" {
+		return writeStats(os.Stdout, records, config)
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := writeStats(file, records, config); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+)
+
+const (
+	statusOK      = "ok"
+	statusError   = "error"
+	statusSkipped = "skipped"
+)
+
+type statusStartEvent struct {
+	Event string `json:"event"`
+	Files int    `json:"files"`
+}
+
+type statusFileEvent struct {
+	Event   string `json:"event"`
+	File    string `json:"file"`
+	Status  string `json:"status"`
+	Records int    `json:"records"`
+	Done    int    `json:"done"`
+	Total   int    `json:"total"`
+}
+
+type statusDoneEvent struct {
+	Event string `json:"event"`
+	Code  int    `json:"code"`
+	RunSummary
+}
+
+var statusLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	total   int
+	done    int
+	summary RunSummary
+}
+
+func openStatus(fd int) error {
+	file := os.NewFile(uintptr(fd), "status")
+	if file == nil {
+		return errors.New(tr("недопустимый дескриптор -status-fd"))
+	}
+	if _, err := file.Stat(); err != nil {
+		return errors.New(tr("дескриптор -status-fd не открыт"))
+	}
+	statusLog.file = file
+	statusLog.encoder = json.NewEncoder(file)
+	return nil
+}
+
+func statusStart(files int) {
+	statusLog.mu.Lock()
+	defer statusLog.mu.Unlock()
+	if statusLog.encoder == nil {
+		return
+	}
+	statusLog.total = files
+	_ = statusLog.encoder.Encode(statusStartEvent{Event: "start", Files: files})
+}
+
+func statusFile(file, status string, records int) {
+	statusLog.mu.Lock()
+	defer statusLog.mu.Unlock()
+	if statusLog.encoder == nil {
+		return
+	}
+	statusLog.done++
+	_ = statusLog.encoder.Encode(statusFileEvent{
+		Event:   "file",
+		File:    file,
+		Status:  status,
+		Records: records,
+		Done:    statusLog.done,
+		Total:   statusLog.total,
+	})
+}
+
+func statusSummary(summary RunSummary) {
+	statusLog.mu.Lock()
+	defer statusLog.mu.Unlock()
+	statusLog.summary = summary
+}
+
+func finishStatus(code int) {
+	statusLog.mu.Lock()
+	defer statusLog.mu.Unlock()
+	if statusLog.encoder == nil {
+		return
+	}
+	if statusLog.summary.Outputs == nil {
+		statusLog.summary.Outputs = []string{}
+	}
+	_ = statusLog.encoder.Encode(statusDoneEvent{Event: "done", Code: code, RunSummary: statusLog.summary})
+	_ = statusLog.file.Close()
+	statusLog.file, statusLog.encoder = nil, nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestStatusHelperProcess(t *testing.T) {
+	if os.Getenv("XML_TO_CSV_STATUS_HELPER") != "1" {
+		return
+	}
+	args := flag.Args()
+	flag.CommandLine = flag.NewFlagSet("xml_to_csv", flag.ContinueOnError)
+	os.Args = append([]string{"xml_to_csv"}, args...)
+	code := run()
+	finishStatus(code)
+	os.Exit(code)
+}
+
+func runWithStatus(t *testing.T, args ...string) (int, []map[string]any) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=^TestStatusHelperProcess$", "--", "-status-fd", "3"}, args...)...)
+	cmd.Env = append(os.Environ(), "XML_TO_CSV_STATUS_HELPER=1")
+	cmd.ExtraFiles = []*os.File{w}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	var events []map[string]any
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var event map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("строка %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	code := 0
+	if err := cmd.Wait(); err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			t.Fatal(err)
+		}
+		code = exitErr.ExitCode()
+	}
+	return code, events
+}
+
+func TestRunStatusFD(t *testing.T) {
+	if isWindows {
+		t.Skip("дополнительные дескрипторы не передаются в Windows")
+	}
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	writeTestFile(t, dataDir, "b.xml", "<ESADout_CU>")
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+
+	for _, mode := range []struct {
+		name  string
+		flags []string
+	}{
+		{"single-pass", nil},
+		{"two-pass", []string{"-two-pass"}},
+		{"spill", []string{"-spill-threshold", "1"}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			output := filepath.Join(outDir, mode.name+".csv")
+			args := append(append([]string{}, mode.flags...), "-output", output, dataDir, config)
+			code, events := runWithStatus(t, args...)
+			if code != exitOK {
+				t.Fatalf("код %d", code)
+			}
+			if len(events) != 4 {
+				t.Fatalf("событий %d; ожидалось 4: %v", len(events), events)
+			}
+			if events[0]["event"] != "start" || events[0]["files"] != float64(2) {
+				t.Errorf("start: %v", events[0])
+			}
+			statuses := make(map[string]any)
+			for _, event := range events[1:3] {
+				if event["event"] != "file" {
+					t.Errorf("ожидалось событие file: %v", event)
+				}
+				statuses[filepath.Base(fmt.Sprint(event["file"]))] = event["status"]
+			}
+			if statuses["a.xml"] != statusOK || statuses["b.xml"] != statusError {
+				t.Errorf("статусы файлов %v", statuses)
+			}
+			done := events[3]
+			outputs, _ := done["outputs"].([]any)
+			if done["event"] != "done" || done["code"] != float64(exitOK) || len(outputs) != 1 || outputs[0] != output || done["records"] != float64(2) || done["errors"] != float64(1) {
+				t.Errorf("done: %v", done)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var timingColumns = []string{"Файл", "Мс"}
+
+var parseTimings struct {
+	mu        sync.Mutex
+	enabled   bool
+	durations map[string]time.Duration
+}
+
+type fileTiming struct {
+	filename string
+	duration time.Duration
+}
+
+func recordTiming(filename string, start time.Time) {
+	elapsed := time.Since(start)
+	parseTimings.mu.Lock()
+	defer parseTimings.mu.Unlock()
+	if parseTimings.durations == nil {
+		parseTimings.durations = make(map[string]time.Duration)
+	}
+	parseTimings.durations[filename] += elapsed
+}
+
+func sortedTimings() []fileTiming {
+	parseTimings.mu.Lock()
+	defer parseTimings.mu.Unlock()
+	timings := make([]fileTiming, 0, len(parseTimings.durations))
+	for filename, duration := range parseTimings.durations {
+		timings = append(timings, fileTiming{filename, duration})
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].duration != timings[j].duration {
+			return timings[i].duration > timings[j].duration
+		}
+		return timings[i].filename < timings[j].filename
+	})
+	return timings
+}
+
+func reportTimings(top int, filename string, config *Config) error {
+	timings := sortedTimings()
+	if top > 0 && len(timings) > 0 {
+		fmt.Printf(tr("Самые медленные файлы (%d из %d):\n"), min(top, len(timings)), len(timings))
+		for _, timing := range timings[:min(top, len(timings))] {
+			fmt.Printf("  %s: %v\n", timing.filename, timing.duration.Round(time.Millisecond))
+		}
+	}
+	if filename == "" {
+		return nil
+	}
+	rows := make([]Record, len(timings))
+	for i, timing := range timings {
+		rows[i] = Record{
+			timingColumns[0]: timing.filename,
+			timingColumns[1]: strconv.FormatInt(timing.duration.Milliseconds(), 10),
+		}
+	}
+	return writeCSV(filename, rows, reportConfig(config, timingColumns))
+}
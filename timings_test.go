@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTimingsFile(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	writeTestFile(t, dataDir, "b.xml", goodsDocument(3))
+	config := filepath.Join(dataDir, "missing.cfg")
+	outDir := t.TempDir()
+
+	for _, mode := range []struct {
+		name  string
+		flags []string
+	}{
+		{"single-pass", nil},
+		{"two-pass", []string{"-two-pass"}},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			parseTimings.durations = nil
+			timings := filepath.Join(outDir, mode.name+"_timings.csv")
+			args := append(append([]string{}, mode.flags...), "-timings-file", timings, "-output", filepath.Join(outDir, mode.name+".csv"), dataDir, config)
+			if code, out := runArgs(t, args...); code != exitOK {
+				t.Fatalf("код %d\n%s", code, out)
+			}
+			lines := readLines(t, timings)
+			if len(lines) != 3 || lines[0] != "Файл;Мс" {
+				t.Fatalf("файл времени:\n%s", strings.Join(lines, "\n"))
+			}
+			for _, line := range lines[1:] {
+				if !strings.HasSuffix(strings.SplitN(line, ";", 2)[0], ".xml") {
+					t.Errorf("строка %q", line)
+				}
+			}
+		})
+	}
+}
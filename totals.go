@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	totalsFileColumn    = "Файл"
+	totalsRecordsColumn = "Записей"
+	totalsGrandLabel    = "Итого"
+)
+
+func parseTotalsColumns(value string) []string {
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func fileTotalsRow(label string, records []Record, columns []string, options map[string]FieldOptions, skipped map[string]int) Record {
+	row := Record{totalsFileColumn: label, totalsRecordsColumn: strconv.Itoa(len(records))}
+	for _, column := range columns {
+		total, numeric := new(big.Rat), false
+		for _, record := range records {
+			value := record[column]
+			if strings.TrimSpace(value) == "" {
+				continue
+			}
+			number, ok := parseDecimal(value, options[column])
+			if !ok {
+				skipped[column]++
+				continue
+			}
+			total.Add(total, number)
+			numeric = true
+		}
+		if numeric {
+			row[column] = formatDecimal(total, options[column])
+		}
+	}
+	return row
+}
+
+func writeFileTotals(filename string, files []string, results [][]Record, excluded []bool, columns []string, config *Config) error {
+	var rows []Record
+	var all []Record
+	skipped := make(map[string]int)
+	for i, file := range files {
+		if excluded[i] {
+			continue
+		}
+		rows = append(rows, fileTotalsRow(file, results[i], columns, config.FieldOptions, skipped))
+		all = append(all, results[i]...)
+	}
+	rows = append(rows, fileTotalsRow(totalsGrandLabel, all, columns, config.FieldOptions, make(map[string]int)))
+	for _, column := range columns {
+		if skipped[column] > 0 {
+			warnf("sum-non-numeric", "", column, "В колонке %q пропущено нечисловых значений при суммировании: %d\n", column, skipped[column])
+		}
+	}
+
+	return writeCSV(filename, rows, reportConfig(config, append([]string{totalsFileColumn, totalsRecordsColumn}, columns...)))
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestFileTotalsRow(t *testing.T) {
+	records := []Record{
+		{"Стоимость": "0,1", "Вес": "1 000,125"},
+		{"Стоимость": "0.2", "Вес": "2"},
+		{"Стоимость": "нет", "Вес": ""},
+	}
+	options := map[string]FieldOptions{"Вес": {"type": "number", "round": "2"}}
+	skipped := make(map[string]int)
+	row := fileTotalsRow("a.xml", records, []string{"Стоимость", "Вес", "Пусто"}, options, skipped)
+	want := Record{totalsFileColumn: "a.xml", totalsRecordsColumn: "3", "Стоимость": "0.3", "Вес": "1002.13"}
+	for column, value := range want {
+		if row[column] != value {
+			t.Errorf("%s = %q; ожидалось %q", column, row[column], value)
+		}
+	}
+	if _, ok := row["Пусто"]; ok {
+		t.Errorf("Пусто = %q; ожидалась пустая ячейка", row["Пусто"])
+	}
+	if skipped["Стоимость"] != 1 {
+		t.Errorf("пропущено %d; ожидалось 1", skipped["Стоимость"])
+	}
+}
+
+func TestReportConfig(t *testing.T) {
+	config := &Config{Gzip: true, Checksum: "sha256", PostCommand: "gpg -e", Preamble: []string{"x"}, Columns: []string{"a"}, Delimiter: ';'}
+	report := reportConfig(config, []string{"b"})
+	if report.Gzip || report.Checksum != "" || report.PostCommand != "" || report.Preamble != nil || report.Columns != nil {
+		t.Errorf("reportConfig сохранил настройки результата: %+v", report)
+	}
+	if report.Delimiter != ';' || len(report.FieldOrder) != 1 || report.FieldOrder[0] != "b" {
+		t.Errorf("reportConfig потерял настройки: %+v", report)
+	}
+	if !config.Gzip || config.Checksum == "" {
+		t.Error("reportConfig изменил исходную конфигурацию")
+	}
+}
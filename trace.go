@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const traceMissing = "MISSING"
+
+var traceColumns = []string{"Файл", "Блок", "Колонка", "Путь"}
+
+var traceLog struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *csv.Writer
+	err    error
+}
+
+func openTraceLog(filename string, delimiter rune) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при создании файла трассировки: %w"), err)
+	}
+	traceLog.file = file
+	traceLog.writer = csv.NewWriter(file)
+	traceLog.writer.Comma = delimiter
+	traceLog.err = traceLog.writer.Write(traceColumns)
+	return nil
+}
+
+func writeTrace(rows [][]string) {
+	traceLog.mu.Lock()
+	defer traceLog.mu.Unlock()
+	if traceLog.writer == nil || traceLog.err != nil {
+		return
+	}
+	traceLog.err = traceLog.writer.WriteAll(rows)
+}
+
+func closeTraceLog() error {
+	if traceLog.file == nil {
+		return nil
+	}
+	traceLog.writer.Flush()
+	err := traceLog.file.Close()
+	if traceLog.err != nil {
+		err = traceLog.err
+	}
+	traceLog.file, traceLog.writer = nil, nil
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при записи файла трассировки: %w"), err)
+	}
+	return nil
+}
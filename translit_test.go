@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Щука", "Shchuka"},
+		{"ЩУКА", "SHCHUKA"},
+		{"Ёж", "Ezh"},
+		{"ЁЖ", "EZH"},
+		{"Юля", "Iulia"},
+		{"Объём", "Obieem"},
+		{"Мальчик", "Malchik"},
+		{"Ь", ""},
+		{"ООО «Ромашка»", "OOO «Romashka»"},
+		{"Model X-200", "Model X-200"},
+		{"Ґанок", "Ganok"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := transliterate(tt.value); got != tt.want {
+			t.Errorf("transliterate(%q) = %q; ожидалось %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+func parseFirstPass(file string, config *Config) ([]Record, error) {
+	muteWarnings(true)
+	defer muteWarnings(false)
+	timed := parseTimings.enabled
+	parseTimings.enabled = false
+	defer func() { parseTimings.enabled = timed }()
+	return parseXML(file, config)
+}
+
+func streamOutput(ctx context.Context, files []string, filename string, config *Config, failFast bool, spillThreshold int) (RunSummary, error) {
+	if spillThreshold > 0 {
+		return writeSpilled(ctx, files, filename, config, failFast, spillThreshold)
+	}
+	return writeTwoPass(ctx, files, filename, config, failFast)
+}
+
+func writeTwoPass(ctx context.Context, files []string, filename string, config *Config, failFast bool) (RunSummary, error) {
+	var summary RunSummary
+	union := make(Record)
+	failed := make(map[string]bool)
+	total, errored := 0, 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return summary, err
+		}
+		recs, err := parseFirstPass(file, config)
+		var mismatch *rootMismatchError
+		if errors.As(err, &mismatch) {
+			warnf("unexpected-root", file, "", "%v\n", err)
+			statusFile(file, statusSkipped, 0)
+			failed[file] = true
+			continue
+		}
+		if err != nil {
+			statusFile(file, statusError, 0)
+			if failFast {
+				return summary, err
+			}
+			warnf("file-error", file, "", "%v\n", err)
+			failed[file] = true
+			errored++
+			continue
+		}
+		statusFile(file, statusOK, len(recs))
+		for _, record := range recs {
+			for key := range record {
+				union[key] = ""
+			}
+		}
+		total += len(recs)
+	}
+	verbosef("Первый проход: файлов %d, записей %d, колонок %d\n", len(files), total, len(union))
+	summary.Blocks, _ = blockTotals()
+
+	err := writeStreamed(filename, union, total, config, func(fn func(Record) error) error {
+		for _, file := range files {
+			if failed[file] {
+				continue
+			}
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			recs, err := parseXML(file, config)
+			if err != nil {
+				return fmt.Errorf(tr("ошибка при повторном чтении файла %s: %w"), file, err)
+			}
+			recs, _ = checkRanges(recs, config, rangeModeWarn, nil)
+			for _, record := range recs {
+				if err := fn(record); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return summary, err
+	}
+	return streamedSummary(summary, filename, total, errored, config), nil
+}
+
+func streamedSummary(summary RunSummary, filename string, total, failed int, config *Config) RunSummary {
+	if total == 0 && !config.HeaderOnEmpty {
+		return summary
+	}
+	summary.Outputs = []string{filename}
+	summary.Records = total
+	summary.Errors = failed
+	return summary
+}
+
+func writeStreamed(filename string, union Record, total int, config *Config, each func(func(Record) error) error) error {
+	if total == 0 && !config.HeaderOnEmpty {
+		fmt.Println(tr("Нет данных... завершение программы"))
+		return nil
+	}
+	if err := checkMaxRows(total, config.MaxRows); err != nil {
+		return err
+	}
+
+	if config.RowNumber {
+		union[rowNumberColumn] = ""
+	}
+	var headers []string
+	if config.AutoMap || config.FlattenAttributes {
+		appendAutoColumns(config, []Record{union})
+	}
+	if total > 0 {
+		headers = getHeaders([]Record{union}, config)
+		reportUnknownColumns([]Record{union}, config.Columns)
+	} else {
+		fmt.Println(tr("Нет данных, записывается только заголовок"))
+		headers = getHeaders(nil, config)
+	}
+
+	return createOutput(filename, config, func(out io.Writer) error {
+		writer, err := newCSVRowWriter(out, config)
+		if err != nil {
+			return err
+		}
+		if err := writer.Write(headers); err != nil {
+			return fmt.Errorf(tr("ошибка при записи заголовков: %w"), err)
+		}
+
+		number := 0
+		err = each(func(record Record) error {
+			if config.RowNumber {
+				number++
+				record[rowNumberColumn] = strconv.Itoa(number)
+			}
+			row := make([]string, len(headers))
+			for i, header := range headers {
+				row[i] = csvCell(config, header, record[header])
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf(tr("ошибка при записи строки: %w"), err)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf(tr("ошибка при записи CSV файла: %w"), err)
+		}
+		return nil
+	})
+}
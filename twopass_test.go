@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTwoPassMatchesSinglePass(t *testing.T) {
+	dataDir := t.TempDir()
+	writeTestFile(t, dataDir, "a.xml", goodsDocument(1, 2))
+	writeTestFile(t, dataDir, "b.xml", `<ESADout_CU xmlns:xi="http://www.w3.org/2001/XInclude"><xi:include href="extra.xml"/>
+	<ESADout_CUGoods><GoodsNumeric>3</GoodsNumeric><Sku>B-3</Sku></ESADout_CUGoods>
+</ESADout_CU>`)
+	writeTestFile(t, dataDir, "c.xml", goodsDocument(4))
+	config := writeTestFile(t, t.TempDir(), "cfg", "Sku=Артикул\n")
+	outDir := t.TempDir()
+
+	single := filepath.Join(outDir, "single.csv")
+	if code, out := runArgs(t, "-output", single, dataDir, config); code != exitOK {
+		t.Fatalf("обычный запуск: код %d\n%s", code, out)
+	}
+	twoPass := filepath.Join(outDir, "two-pass.csv")
+	code, out := runArgs(t, "-two-pass", "-output", twoPass, dataDir, config)
+	if code != exitOK {
+		t.Fatalf("-two-pass: код %d\n%s", code, out)
+	}
+
+	want, err := os.ReadFile(single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(twoPass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("-two-pass:\n%s\nобычный запуск:\n%s", got, want)
+	}
+	if n := strings.Count(out, "xi:include"); n != 1 {
+		t.Errorf("сообщение о xi:include выведено %d раз; ожидался 1\n%s", n, out)
+	}
+}
+
+func BenchmarkTwoPass(b *testing.B) {
+	dataDir := b.TempDir()
+	for i := 0; i < 20; i++ {
+		numbers := make([]int, 200)
+		for j := range numbers {
+			numbers[j] = i*200 + j + 1
+		}
+		name := filepath.Join(dataDir, fmt.Sprintf("d%02d.xml", i))
+		if err := os.WriteFile(name, []byte(goodsDocument(numbers...)), 0o644); err != nil {
+			b.Fatal(err)
+		}
+	}
+	config := filepath.Join(dataDir, "missing.cfg")
+	output := filepath.Join(b.TempDir(), "result.csv")
+
+	for _, mode := range []struct {
+		name  string
+		flags []string
+	}{
+		{"single-pass", nil},
+		{"two-pass", []string{"-two-pass"}},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			args := append(append([]string{}, mode.flags...), "-if-exists", "overwrite", "-output", output, dataDir, config)
+			for i := 0; i < b.N; i++ {
+				if code := benchRun(b, args...); code != exitOK {
+					b.Fatalf("код %d", code)
+				}
+			}
+		})
+	}
+}
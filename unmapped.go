@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/beevik/etree"
+)
+
+var unmappedLeaves struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func collectUnmapped(block *etree.Element, elements map[string][]*etree.Element, countFields map[string]string, config *Config) {
+	covered := make(map[*etree.Element]bool)
+	for _, elems := range elements {
+		for _, elem := range elems {
+			covered[elem] = true
+		}
+	}
+	for path := range countFields {
+		for _, elem := range block.FindElements(blockPath(path)) {
+			covered[elem] = true
+		}
+	}
+	for _, nested := range config.Nested {
+		for _, elem := range block.FindElements(blockPath(nested.FieldMap[parserOpenBlockTagLiteral])) {
+			covered[elem] = true
+		}
+	}
+
+	found := make(map[string]int)
+	var walk func(elem *etree.Element)
+	walk = func(elem *etree.Element) {
+		for _, child := range elem.ChildElements() {
+			if covered[child] {
+				continue
+			}
+			if len(child.ChildElements()) > 0 {
+				walk(child)
+			} else if strings.TrimSpace(child.Text()) != "" {
+				found[child.Tag]++
+			}
+		}
+	}
+	walk(block)
+	if len(found) == 0 {
+		return
+	}
+
+	unmappedLeaves.mu.Lock()
+	defer unmappedLeaves.mu.Unlock()
+	if unmappedLeaves.counts == nil {
+		unmappedLeaves.counts = make(map[string]int)
+	}
+	for tag, count := range found {
+		unmappedLeaves.counts[tag] += count
+	}
+}
+
+func reportUnmapped() int {
+	unmappedLeaves.mu.Lock()
+	defer unmappedLeaves.mu.Unlock()
+	tags := make([]string, 0, len(unmappedLeaves.counts))
+	for tag := range unmappedLeaves.counts {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	for _, tag := range tags {
+		warnf("unmapped-tag", "", tag, "Непустой элемент %s не сопоставлен ни одной колонке (вхождений: %d)\n", tag, unmappedLeaves.counts[tag])
+	}
+	if len(tags) > 0 {
+		fmt.Printf(tr("Несопоставленных тегов: %d\n"), len(tags))
+	}
+	return len(tags)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const typoDistance = 2
+
+type configReference struct {
+	name   string
+	attr   bool
+	source string
+}
+
+func documentNames(doc *etree.Document) (map[string]bool, map[string]bool) {
+	tags := make(map[string]bool)
+	attrs := make(map[string]bool)
+	root := doc.Root()
+	if root != nil && root.Tag == "schema" {
+		for _, elem := range root.FindElements("//element[@name]") {
+			tags[localName(elem.SelectAttrValue("name", ""))] = true
+		}
+		for _, elem := range root.FindElements("//attribute[@name]") {
+			attrs[localName(elem.SelectAttrValue("name", ""))] = true
+		}
+		return tags, attrs
+	}
+	for _, elem := range doc.FindElements("//*") {
+		tags[elem.Tag] = true
+		for _, attr := range elem.Attr {
+			attrs[attr.Key] = true
+		}
+	}
+	return tags, attrs
+}
+
+func localName(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+func pathNames(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if i := strings.Index(segment, "["); i >= 0 {
+			segment = segment[:i]
+		}
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, "*()@") {
+			continue
+		}
+		names = append(names, localName(segment))
+	}
+	return names
+}
+
+func mappingReferences(fieldMap map[string]string, skipRules []SkipRule, nested []*NestedDefinition) []configReference {
+	var refs []configReference
+	addPath := func(path, source string) {
+		for _, name := range pathNames(path) {
+			refs = append(refs, configReference{name: name, source: source})
+		}
+	}
+	keys := make([]string, 0, len(fieldMap))
+	for key := range fieldMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if key == parserOpenBlockTagLiteral {
+			addPath(fieldMap[key], parserOpenBlockTagLiteral)
+			continue
+		}
+		if strings.HasPrefix(key, countPrefix) {
+			addPath(strings.TrimPrefix(key, countPrefix), key)
+			continue
+		}
+		for _, alternative := range strings.Split(key, "|") {
+			path, attr := splitAttr(strings.TrimSpace(alternative))
+			addPath(path, key)
+			if attr != "" {
+				refs = append(refs, configReference{name: localName(attr), attr: true, source: key})
+			}
+		}
+	}
+	for _, rule := range skipRules {
+		addPath(rule.Tag, skipIfPrefix+rule.Tag)
+	}
+	for _, definition := range nested {
+		refs = append(refs, mappingReferences(definition.FieldMap, nil, nil)...)
+	}
+	return refs
+}
+
+func suggestName(name string, known map[string]bool) string {
+	best, bestDistance := "", typoDistance+1
+	for candidate := range known {
+		distance := editDistance(strings.ToLower(name), strings.ToLower(candidate))
+		if distance < bestDistance || distance == bestDistance && candidate < best {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	first, second := []rune(a), []rune(b)
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(second)]
+}
+
+func validateConfigReferences(filename string, config *Config) (int, error) {
+	doc, err := readDocument(filename, config)
+	if err != nil {
+		return 0, fmt.Errorf(tr("ошибка при чтении файла %s: %w"), filename, err)
+	}
+	tags, attrs := documentNames(doc)
+
+	refs := mappingReferences(config.FieldMap, config.SkipRules, config.Nested)
+	for _, block := range config.BlockTypes {
+		refs = append(refs, mappingReferences(block.FieldMap, block.SkipRules, block.Nested)...)
+	}
+
+	missing := 0
+	reported := make(map[configReference]bool)
+	for _, ref := range refs {
+		known := tags
+		if ref.attr {
+			known = attrs
+		}
+		if known[ref.name] || reported[ref] {
+			continue
+		}
+		reported[ref] = true
+		missing++
+		kind := tr("Тег")
+		if ref.attr {
+			kind = tr("Атрибут")
+		}
+		if suggestion := suggestName(ref.name, known); suggestion != "" {
+			fmt.Printf(tr("%s %q из %q не встречается в %s, возможно, имелся в виду %q\n"), kind, ref.name, ref.source, filename, suggestion)
+		} else {
+			fmt.Printf(tr("%s %q из %q не встречается в %s\n"), kind, ref.name, ref.source, filename)
+		}
+	}
+	if missing == 0 {
+		fmt.Printf(tr("Все теги и атрибуты конфигурации найдены в %s\n"), filename)
+	}
+	return missing, nil
+}
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+const maxDecimalPlaces = 30
+
+var (
+	numberSpaceRemover    = strings.NewReplacer(" ", "", "\u00A0", "", "\u202F", "")
+	numberGroupSeparators string
+	numberAutodetect      = true
+
+	dateLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02",
+		"02.01.2006",
+		"02.01.2006 15:04:05",
+	}
+)
+
+func normalizeNumber(value string, options FieldOptions) (string, bool) {
+	if decimal := options["decimal"]; decimal != "" {
+		group, _ := optionText(options["group"])
+		return normalizeStrictNumber(value, decimal, group)
+	}
+	if !numberAutodetect {
+		return normalizeStrictNumber(value, ".", "")
+	}
+	value = numberSpaceRemover.Replace(strings.TrimSpace(value))
+	if numberGroupSeparators != "" {
+		value = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(numberGroupSeparators, r) {
+				return -1
+			}
+			return r
+		}, value)
+	}
+	if value == "" {
+		return "", false
+	}
+
+	lastComma := strings.LastIndex(value, ",")
+	lastDot := strings.LastIndex(value, ".")
+	switch {
+	case lastComma >= 0 && lastDot >= 0:
+		if lastComma > lastDot {
+			value = strings.ReplaceAll(value, ".", "")
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastComma >= 0:
+		if strings.Count(value, ",") == 1 {
+			value = strings.Replace(value, ",", ".", 1)
+		} else {
+			value = strings.ReplaceAll(value, ",", "")
+		}
+	case lastDot >= 0 && strings.Count(value, ".") > 1:
+		value = strings.ReplaceAll(value, ".", "")
+	}
+
+	if strings.Count(value, ".") > 1 || strings.Trim(value, "0123456789.-+eE") != "" {
+		return "", false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return "", false
+	}
+	return value, true
+}
+
+func normalizeStrictNumber(value, decimal, group string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if strings.Contains(group, " ") {
+		value = strings.NewReplacer("\u00A0", " ", "\u202F", " ").Replace(value)
+	}
+	sign := ""
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		sign, value = value[:1], value[1:]
+	}
+	integer, fraction, hasFraction := strings.Cut(value, decimal)
+	if group != "" && strings.ContainsAny(integer, group) {
+		var digits strings.Builder
+		run, first := 0, true
+		for _, r := range integer {
+			if strings.ContainsRune(group, r) {
+				if run == 0 || run > 3 || !first && run != 3 {
+					return "", false
+				}
+				run, first = 0, false
+				continue
+			}
+			digits.WriteRune(r)
+			run++
+		}
+		if run != 3 {
+			return "", false
+		}
+		integer = digits.String()
+	}
+	if !onlyDigits(integer) || !onlyDigits(fraction) || integer == "" && fraction == "" || hasFraction && fraction == "" {
+		return "", false
+	}
+	if hasFraction {
+		integer += "." + fraction
+	}
+	return sign + integer, true
+}
+
+func checkNumberFormat(options FieldOptions) error {
+	decimal := options["decimal"]
+	switch decimal {
+	case "", ".", ",":
+	default:
+		return fmt.Errorf(tr("decimal=%q: ожидается . или ,"), decimal)
+	}
+	group, err := optionText(options["group"])
+	if err != nil {
+		return fmt.Errorf("group=%s: %w", options["group"], err)
+	}
+	switch {
+	case group == "":
+	case decimal == "":
+		return errors.New(tr("group= задаётся только вместе с decimal="))
+	case strings.Contains(group, decimal):
+		return fmt.Errorf(tr("group=%q совпадает с decimal=%q"), group, decimal)
+	case strings.ContainsAny(group, "0123456789+-"):
+		return fmt.Errorf(tr("недопустимый разделитель разрядов group=%q"), group)
+	}
+	return nil
+}
+
+func withDecimal(value string, options FieldOptions) string {
+	if decimal := options["decimal"]; decimal != "" {
+		return strings.Replace(value, ".", decimal, 1)
+	}
+	return value
+}
+
+func onlyDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func parseNumber(value string, options FieldOptions) (float64, bool) {
+	normalized, ok := normalizeNumber(value, options)
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(normalized, 64)
+	return number, err == nil
+}
+
+func parseDecimal(value string, options FieldOptions) (*big.Rat, bool) {
+	normalized, ok := normalizeNumber(value, options)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetString(normalized)
+}
+
+func formatDecimal(number *big.Rat, options FieldOptions) string {
+	if places, err := strconv.Atoi(options["round"]); err == nil && places >= 0 {
+		return number.FloatString(places)
+	}
+	places, scale, ten := 0, big.NewInt(1), big.NewInt(10)
+	for places < maxDecimalPlaces && new(big.Int).Rem(scale, number.Denom()).Sign() != 0 {
+		scale.Mul(scale, ten)
+		places++
+	}
+	return number.FloatString(places)
+}
+
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func roundNumber(value string, places int, options FieldOptions) (string, bool) {
+	normalized, ok := normalizeNumber(value, options)
+	if !ok {
+		return value, false
+	}
+	number, ok := new(big.Rat).SetString(normalized)
+	if !ok {
+		return value, false
+	}
+	return number.FloatString(places), true
+}
+
+func roundInteger(value, mode string, options FieldOptions) (string, bool) {
+	normalized, ok := normalizeNumber(value, options)
+	if !ok {
+		return value, false
+	}
+	number, ok := new(big.Rat).SetString(normalized)
+	if !ok {
+		return value, false
+	}
+	floor := func(r *big.Rat) *big.Int {
+		return new(big.Int).Div(r.Num(), r.Denom())
+	}
+	half := big.NewRat(1, 2)
+	var integer *big.Int
+	switch {
+	case mode == roundFloor:
+		integer = floor(number)
+	case mode == roundCeil:
+		integer = floor(number.Neg(number))
+		integer.Neg(integer)
+	case number.Sign() < 0:
+		integer = floor(number.Sub(half, number))
+		integer.Neg(integer)
+	default:
+		integer = floor(number.Add(number, half))
+	}
+	return integer.String(), true
+}
+
+func numericField(options FieldOptions) bool {
+	return options["type"] == "number" || options["type"] == "int"
+}
+
+func formatAmount(value string, places int, locale string, options FieldOptions) (string, bool) {
+	rounded, ok := roundNumber(value, places, options)
+	if !ok || locale == localePlain {
+		return rounded, ok
+	}
+	groupSep, decimalSep := ",", "."
+	if locale == localeRU {
+		groupSep, decimalSep = "\u00A0", ","
+	}
+	sign := ""
+	if strings.HasPrefix(rounded, "-") {
+		sign, rounded = "-", rounded[1:]
+	}
+	integer, fraction, _ := strings.Cut(rounded, ".")
+	var grouped strings.Builder
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(groupSep)
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		return sign + grouped.String() + decimalSep + fraction, true
+	}
+	return sign + grouped.String(), true
+}
+
+func trimAffixes(value string, options FieldOptions) string {
+	if prefix := options["trim-prefix"]; prefix != "" {
+		value = strings.TrimPrefix(value, prefix)
+	}
+	if suffix := options["trim-suffix"]; suffix != "" {
+		value = strings.TrimSuffix(value, suffix)
+	}
+	if cutset := options["trim-left"]; cutset != "" {
+		value = strings.TrimLeft(value, cutset)
+	}
+	if cutset := options["trim-right"]; cutset != "" {
+		value = strings.TrimRight(value, cutset)
+	}
+	return value
+}
+
+func joinLines(value, separator string) string {
+	var lines []string
+	for _, line := range strings.FieldsFunc(value, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, separator)
+}
+
+func csvCell(config *Config, field, value string) string {
+	if value == "" || config.FieldOptions[field]["excel-text"] != "true" {
+		return value
+	}
+	return `="` + strings.ReplaceAll(value, `"`, `""`) + `"`
+}
+
+func excelTextValue(value string) string {
+	if len(value) >= 3 && strings.HasPrefix(value, `="`) && strings.HasSuffix(value, `"`) {
+		return strings.ReplaceAll(value[2:len(value)-1], `""`, `"`)
+	}
+	return value
+}
+
+func optionText(value string) (string, error) {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return strconv.Unquote(value)
+	}
+	return value, nil
+}
+
+func parseKeyNormalization(value string) (func(string) string, error) {
+	casefold, nfc := false, false
+	for _, mode := range strings.Split(value, ",") {
+		switch strings.TrimSpace(mode) {
+		case "":
+		case "casefold":
+			casefold = true
+		case "nfc":
+			nfc = true
+		default:
+			return nil, fmt.Errorf(tr("неизвестное преобразование ключа %q, ожидается casefold или nfc"), mode)
+		}
+	}
+	return func(key string) string {
+		if casefold {
+			key = cases.Fold().String(key)
+		}
+		if nfc {
+			key = norm.NFC.String(key)
+		}
+		return key
+	}, nil
+}
+
+func truncateValue(value string, limit int, ellipsis string) string {
+	runes := []rune(value)
+	if len(runes) <= limit {
+		return value
+	}
+	keep := limit - utf8.RuneCountInString(ellipsis)
+	return string(runes[:keep]) + ellipsis
+}
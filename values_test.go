@@ -0,0 +1,138 @@
+package main
+
+import "testing"
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		value   string
+		options FieldOptions
+		want    string
+		ok      bool
+	}{
+		{"1 234,56", nil, "1234.56", true},
+		{"1.234,56", nil, "1234.56", true},
+		{"1,234.56", nil, "1234.56", true},
+		{"12,5", nil, "12.5", true},
+		{"1,234,567", nil, "1234567", true},
+		{"1.234.567", nil, "1234567", true},
+		{"1 000", nil, "1000", true},
+		{" -3.5 ", nil, "-3.5", true},
+		{"1e3", nil, "1e3", true},
+		{"", nil, "", false},
+		{"abc", nil, "", false},
+		{"12 кг", nil, "", false},
+		{"1 234,56", FieldOptions{"decimal": ",", "group": `" "`}, "1234.56", true},
+		{"1,234,567.8", FieldOptions{"decimal": ".", "group": ","}, "1234567.8", true},
+		{"12,34.5", FieldOptions{"decimal": ".", "group": ","}, "", false},
+		{"1.5", FieldOptions{"decimal": ","}, "", false},
+		{"5,", FieldOptions{"decimal": ","}, "", false},
+		{"+7,25", FieldOptions{"decimal": ","}, "+7.25", true},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeNumber(tt.value, tt.options)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeNumber(%q, %v) = %q, %v; ожидалось %q, %v", tt.value, tt.options, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestNormalizeNumberNoAutodetect(t *testing.T) {
+	defer func(saved bool) { numberAutodetect = saved }(numberAutodetect)
+	numberAutodetect = false
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{"1234.5", "1234.5", true},
+		{"1,5", "", false},
+		{"1 234", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := normalizeNumber(tt.value, nil)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeNumber(%q) = %q, %v; ожидалось %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRoundNumber(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int
+		want   string
+		ok     bool
+	}{
+		{"2.345", 2, "2.35", true},
+		{"-2.345", 2, "-2.35", true},
+		{"2.344", 2, "2.34", true},
+		{"1 234,5", 0, "1235", true},
+		{"7", 3, "7.000", true},
+		{"0.1", 1, "0.1", true},
+		{"abc", 2, "abc", false},
+	}
+	for _, tt := range tests {
+		got, ok := roundNumber(tt.value, tt.places, nil)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("roundNumber(%q, %d) = %q, %v; ожидалось %q, %v", tt.value, tt.places, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRoundInteger(t *testing.T) {
+	tests := []struct {
+		value string
+		mode  string
+		want  string
+		ok    bool
+	}{
+		{"2,5", roundNearest, "3", true},
+		{"-2.5", roundNearest, "-3", true},
+		{"2.4", "", "2", true},
+		{"2.9", roundFloor, "2", true},
+		{"-2.1", roundFloor, "-3", true},
+		{"2.1", roundCeil, "3", true},
+		{"-2.9", roundCeil, "-2", true},
+		{"1 234", roundNearest, "1234", true},
+		{"abc", roundNearest, "abc", false},
+	}
+	for _, tt := range tests {
+		got, ok := roundInteger(tt.value, tt.mode, nil)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("roundInteger(%q, %q) = %q, %v; ожидалось %q, %v", tt.value, tt.mode, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCSVCell(t *testing.T) {
+	config := &Config{FieldOptions: map[string]FieldOptions{"Код": {"excel-text": "true"}}}
+	tests := []struct {
+		field string
+		value string
+		want  string
+	}{
+		{"Код", "00123", `="00123"`},
+		{"Код", `a"b`, `="a""b"`},
+		{"Код", "", ""},
+		{"Название", "00123", "00123"},
+	}
+	for _, tt := range tests {
+		if got := csvCell(config, tt.field, tt.value); got != tt.want {
+			t.Errorf("csvCell(%q, %q) = %q; ожидалось %q", tt.field, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestExcelTextValue(t *testing.T) {
+	config := &Config{FieldOptions: map[string]FieldOptions{"Код": {"excel-text": "true"}}}
+	for _, value := range []string{"0012", `a"b`, `""`, "=1", ""} {
+		if got := excelTextValue(csvCell(config, "Код", value)); got != value {
+			t.Errorf("excelTextValue(csvCell(%q)) = %q", value, got)
+		}
+	}
+	for _, value := range []string{"0012", `="`, `="x`, `=x"`} {
+		if got := excelTextValue(value); got != value {
+			t.Errorf("excelTextValue(%q) = %q; ожидалось без изменений", value, got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+type Warning struct {
+	Type    string `json:"type"`
+	File    string `json:"file"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var warningLog struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	err     error
+	muted   bool
+}
+
+func openWarningLog(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при создании файла предупреждений: %w"), err)
+	}
+	warningLog.file = file
+	warningLog.encoder = json.NewEncoder(file)
+	return nil
+}
+
+func closeWarningLog() error {
+	if warningLog.file == nil {
+		return nil
+	}
+	err := warningLog.file.Close()
+	if warningLog.err != nil {
+		err = warningLog.err
+	}
+	warningLog.file, warningLog.encoder = nil, nil
+	if err != nil {
+		return fmt.Errorf(tr("ошибка при записи файла предупреждений: %w"), err)
+	}
+	return nil
+}
+
+func muteWarnings(muted bool) {
+	warningLog.mu.Lock()
+	defer warningLog.mu.Unlock()
+	warningLog.muted = muted
+}
+
+func warnf(kind, file, field, format string, args ...any) {
+	warningLog.mu.Lock()
+	defer warningLog.mu.Unlock()
+	if warningLog.muted {
+		return
+	}
+	message := fmt.Sprintf(tr(format), args...)
+	fmt.Print(message)
+
+	if warningLog.encoder == nil || warningLog.err != nil {
+		return
+	}
+	warningLog.err = warningLog.encoder.Encode(Warning{
+		Type:    kind,
+		File:    file,
+		Field:   field,
+		Message: strings.TrimSpace(message),
+	})
+}
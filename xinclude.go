@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	xincludeNamespace = "http://www.w3.org/2001/XInclude"
+	maxIncludeDepth   = 16
+)
+
+func hasDoctype(doc *etree.Document) bool {
+	for _, token := range doc.Child {
+		if directive, ok := token.(*etree.Directive); ok && strings.HasPrefix(strings.TrimSpace(directive.Data), "DOCTYPE") {
+			return true
+		}
+	}
+	return false
+}
+
+func findIncludes(elem *etree.Element) []*etree.Element {
+	var includes []*etree.Element
+	for _, child := range elem.ChildElements() {
+		if child.Tag == "include" && child.NamespaceURI() == xincludeNamespace {
+			includes = append(includes, child)
+			continue
+		}
+		includes = append(includes, findIncludes(child)...)
+	}
+	return includes
+}
+
+func expandIncludes(doc *etree.Document, filename string, config *Config, depth int) error {
+	if depth > maxIncludeDepth {
+		return fmt.Errorf(tr("превышена глубина вложенности xi:include (%d)"), maxIncludeDepth)
+	}
+	var includes []*etree.Element
+	for _, root := range doc.ChildElements() {
+		includes = append(includes, findIncludes(root)...)
+	}
+
+	for _, include := range includes {
+		href := include.SelectAttrValue("href", "")
+		if href == "" || strings.Contains(href, "://") {
+			return fmt.Errorf(tr("неподдерживаемое включение xi:include href=%q"), href)
+		}
+		path := href
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(filename), href)
+		}
+
+		var replacement etree.Token
+		switch parse := include.SelectAttrValue("parse", "xml"); parse {
+		case "xml":
+			included, err := readDocument(path, config)
+			if err != nil {
+				return fmt.Errorf(tr("ошибка при чтении включения %s: %w"), path, err)
+			}
+			if config.NoDTD && hasDoctype(included) {
+				return fmt.Errorf(tr("включение %s содержит DTD"), path)
+			}
+			if err := expandIncludes(included, path, config, depth+1); err != nil {
+				return err
+			}
+			if replacement = included.Root(); replacement == nil {
+				return fmt.Errorf(tr("включение %s не содержит корневого элемента"), path)
+			}
+		case "text":
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf(tr("ошибка при чтении включения %s: %w"), path, err)
+			}
+			replacement = etree.NewText(string(data))
+		default:
+			return fmt.Errorf(tr("неподдерживаемое значение parse=%q в xi:include"), parse)
+		}
+
+		parent := include.Parent()
+		parent.InsertChildAt(include.Index(), replacement)
+		parent.RemoveChild(include)
+	}
+	return nil
+}
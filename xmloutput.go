@@ -0,0 +1,80 @@
+package main
+
+import (
+	"io"
+	"strconv"
+	"unicode"
+
+	"github.com/beevik/etree"
+)
+
+const (
+	xmlRootElement     = "records"
+	xmlRecordElement   = "record"
+	xmlFieldElement    = "field"
+	xmlMetadataElement = "metadata"
+)
+
+func isXMLName(name string) bool {
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+func writeXML(filename string, records []Record, config *Config) error {
+	return createOutput(filename, config, func(out io.Writer) error {
+		encodingName := "UTF-8"
+		switch outputEncoding(config) {
+		case encodingCP1251:
+			encodingName = "windows-1251"
+		case encodingUTF16LE:
+			encodingName = "UTF-16"
+		}
+		doc := etree.NewDocument()
+		doc.CreateProcInst("xml", `version="1.0" encoding="`+encodingName+`"`)
+		root := doc.CreateElement(xmlRootElement)
+		if config.Metadata != nil {
+			metadata := root.CreateElement(xmlMetadataElement)
+			metadata.CreateElement("tool").SetText(config.Metadata.Tool)
+			metadata.CreateElement("version").SetText(config.Metadata.Version)
+			metadata.CreateElement("created").SetText(config.Metadata.Created)
+			metadata.CreateElement("input").SetText(config.Metadata.Input)
+			metadata.CreateElement("files").SetText(strconv.Itoa(config.Metadata.Files))
+			lines := metadata.CreateElement("config")
+			for _, line := range config.Metadata.Config {
+				lines.CreateElement("line").SetText(line)
+			}
+		}
+
+		headers := getHeaders(records, config)
+		for _, record := range records {
+			elem := root.CreateElement(xmlRecordElement)
+			for _, header := range headers {
+				value, ok := record[header]
+				if !ok {
+					continue
+				}
+				if isXMLName(header) {
+					elem.CreateElement(header).SetText(value)
+					continue
+				}
+				field := elem.CreateElement(xmlFieldElement)
+				field.CreateAttr("name", header)
+				field.SetText(value)
+			}
+		}
+
+		settings := etree.NewIndentSettings()
+		settings.Spaces = 2
+		settings.UseCRLF = config.CRLF
+		doc.IndentWithSettings(settings)
+		_, err := doc.WriteTo(out)
+		return err
+	})
+}